@@ -6,17 +6,26 @@ import (
 	"path/filepath"
 	"text/template"
 
+	"github.com/upamune/claude-code-pull-worker/internal/config"
+	"github.com/upamune/claude-code-pull-worker/internal/discordbot"
+	"github.com/upamune/claude-code-pull-worker/internal/handlers"
 )
 
 type CLI struct {
 	Server Server `cmd:"" help:"Run the webhook server (default)" default:"1"`
 	SystemdInstall SystemdInstall `cmd:"" help:"Generate systemd service file"`
+	DiscordRegister DiscordRegister `cmd:"" help:"Register the /claude slash command with Discord"`
 }
 
 type Server struct {
 	ConfigFile string `help:"Path to config file" env:"CONFIG_FILE"`
 }
 
+type DiscordRegister struct {
+	ApplicationID string `help:"Discord application ID (default: DISCORD_APPLICATION_ID)"`
+	BotToken      string `help:"Discord bot token (default: DISCORD_BOT_TOKEN)"`
+}
+
 type SystemdInstall struct {
 	User       string `help:"User to run the service as" required:""`
 	WorkingDir string `help:"Working directory for the service" type:"path" default:"."`
@@ -113,4 +122,32 @@ func (s *SystemdInstall) Run() error {
 	fmt.Println("  sudo systemctl start claude-code-pull-worker")
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (d *DiscordRegister) Run() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	applicationID := d.ApplicationID
+	if applicationID == "" {
+		applicationID = cfg.DiscordApplicationID
+	}
+	token := d.BotToken
+	if token == "" {
+		token = cfg.DiscordBotToken
+	}
+	if applicationID == "" || token == "" {
+		return fmt.Errorf("the Discord application ID and bot token are required")
+	}
+
+	client := discordbot.NewClient(cfg.DiscordAPIBaseURL, token)
+	if err := client.RegisterCommand(applicationID, handlers.DiscordCommand); err != nil {
+		return fmt.Errorf("failed to register command: %w", err)
+	}
+
+	fmt.Printf("Registered /%s for application %s\n", handlers.DiscordCommand.Name, applicationID)
+	fmt.Println("\nSet the Interactions Endpoint URL of the application to https://<your-host>/discord/interactions")
+	return nil
+}
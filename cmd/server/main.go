@@ -13,7 +13,13 @@ import (
 	"github.com/upamune/claude-code-pull-worker/internal/config"
 	"github.com/upamune/claude-code-pull-worker/internal/database"
 	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/discordbot"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
 	"github.com/upamune/claude-code-pull-worker/internal/handlers"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/metrics"
+	"github.com/upamune/claude-code-pull-worker/internal/slackbot"
+	"github.com/upamune/claude-code-pull-worker/internal/tracing"
 	"github.com/upamune/claude-code-pull-worker/internal/worker"
 )
 
@@ -26,6 +32,10 @@ func main() {
 		err := cli.SystemdInstall.Run()
 		ctx.FatalIfErrorf(err)
 		return
+	case "discord-register":
+		err := cli.DiscordRegister.Run()
+		ctx.FatalIfErrorf(err)
+		return
 	default:
 		runServer(cli.Server)
 	}
@@ -37,6 +47,18 @@ func runServer(serverCmd Server) {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingEnabled)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	if cfg.TracingEnabled {
+		log.Println("Exporting traces over OTLP")
+	}
+
 	// Initialize database
 	database, err := database.New("claude-code-pull-worker.db")
 	if err != nil {
@@ -47,33 +69,73 @@ func runServer(serverCmd Server) {
 	// Create queries instance
 	queries := db.New(database)
 
+	// Create and start queue worker pool
+	workerPool := worker.NewPool(queries, cfg.WorkerPoolSize)
+	discordClient := discordbot.NewClient(cfg.DiscordAPIBaseURL, cfg.DiscordBotToken)
+	workerPool.SetDiscordClient(discordClient)
+	slackClient := slackbot.NewClient(cfg.SlackAPIBaseURL, cfg.SlackBotToken)
+	workerPool.SetSlackClient(slackClient)
+	workerPool.SetCallbackAllowedHosts(cfg.CallbackAllowedHosts)
+	if cfg.GitHubToken != "" {
+		workerPool.SetGitHubCommenter(githubbot.NewClient(cfg.GitHubAPIBaseURL, cfg.GitHubToken))
+	}
+	workerCtx, cancelWorker := context.WithCancel(context.Background())
+	go workerPool.Start(workerCtx)
+	log.Println("Queue worker pool started")
+
 	// Initialize handlers
-	adminHandler, err := handlers.NewAdminHandler(queries)
+	adminHandler, err := handlers.NewAdminHandler(queries, workerPool)
 	if err != nil {
 		log.Fatalf("Failed to initialize admin handler: %v", err)
 	}
 
 	webhookHandler := handlers.NewWebhookExecutionHandler(queries)
 
-	// Create and start queue worker
-	queueWorker := worker.NewQueueWorker(queries)
-	workerCtx, cancelWorker := context.WithCancel(context.Background())
-	go queueWorker.Start(workerCtx)
-	log.Println("Queue worker started")
-
 	// Setup routes
 	r := mux.NewRouter()
+	r.Use(logging.Middleware)
 	
 	// Register admin routes
 	adminHandler.RegisterRoutes(r)
 	
 	// Register webhook execution routes
 	r.HandleFunc("/webhooks/{uuid}", webhookHandler.HandleWebhookExecution).Methods("POST")
+	r.HandleFunc("/webhooks/{uuid}/jobs/{id}", webhookHandler.HandleJobStatus).Methods("GET")
+	
+	// Discord bot interactions endpoint
+	if cfg.DiscordPublicKey != "" {
+		publicKey, err := discordbot.ParsePublicKey(cfg.DiscordPublicKey)
+		if err != nil {
+			log.Fatalf("Invalid DISCORD_PUBLIC_KEY: %v", err)
+		}
+		if !discordClient.HasToken() {
+			log.Printf("DISCORD_BOT_TOKEN is not set; results of Discord commands cannot be posted back")
+		}
+		interactionsHandler := handlers.NewDiscordInteractionsHandler(webhookHandler, publicKey, discordClient)
+		r.HandleFunc("/discord/interactions", interactionsHandler.HandleInteraction).Methods("POST")
+	}
+	
+	// Slack slash commands and Events API
+	if cfg.SlackSigningSecret != "" {
+		if !slackClient.HasToken() {
+			log.Printf("SLACK_BOT_TOKEN is not set; results of Slack mentions cannot be posted back")
+		}
+		slackHandler := handlers.NewSlackIntegrationHandler(webhookHandler, cfg.SlackSigningSecret, slackClient)
+		r.HandleFunc("/integrations/slack/{uuid}", slackHandler.HandleSlack).Methods("POST")
+	}
+	
+	// GitHub webhook deliveries, verified with the secret of each webhook's integration
+	githubHandler := handlers.NewGitHubWebhookHandler(webhookHandler)
+	r.HandleFunc("/integrations/github/{uuid}", githubHandler.HandleDelivery).Methods("POST")
 	
 	// Legacy endpoint (for backward compatibility)
 	r.HandleFunc("/webhook", handleLegacyWebhook).Methods("POST")
 	r.HandleFunc("/health", handleHealth).Methods("GET")
 
+	// Prometheus metrics
+	metrics.RegisterQueue(queries)
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
 	// Serve static files if needed
 	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
 
@@ -104,15 +166,20 @@ func runServer(serverCmd Server) {
 
 	log.Println("Shutting down...")
 
-	// Stop worker
+	// Stop workers
 	cancelWorker()
-	queueWorker.Stop()
+	workerPool.Stop()
 
 	// Shutdown HTTP server
 	if err := srv.Shutdown(context.Background()); err != nil {
 		log.Printf("Server shutdown error: %v", err)
 	}
 
+	// Flush the spans of the last jobs
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Printf("Tracing shutdown error: %v", err)
+	}
+
 	log.Println("Server stopped")
 }
 
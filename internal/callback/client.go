@@ -0,0 +1,116 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the request body, formatted as "sha256=<hex>"
+	SignatureHeader = "X-Signature-256"
+	// JobIDHeader carries the ID of the job the callback reports on
+	JobIDHeader = "X-Job-ID"
+)
+
+// ErrForbiddenAddress is returned for callback URLs that resolve to a loopback,
+// private or link-local address, such as the worker's own admin API
+var ErrForbiddenAddress = errors.New("callback address is not allowed")
+
+// Client POSTs job results to caller-supplied callback URLs. Callback URLs are
+// chosen by API callers, so the client only connects to public addresses unless
+// the host is explicitly allowed with SetAllowedHosts.
+type Client struct {
+	httpClient   *http.Client
+	allowedHosts map[string]bool
+}
+
+func NewClient(timeout time.Duration) *Client {
+	c := &Client{allowedHosts: map[string]bool{}}
+
+	// The resolved address is checked right before connecting, so neither DNS
+	// names nor redirects can point a callback at an internal service. Proxies
+	// are not used since they would connect on the client's behalf.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = c.dialContext
+	c.httpClient = &http.Client{Timeout: timeout, Transport: transport}
+	return c
+}
+
+// SetAllowedHosts lets callbacks reach the given hosts, as written in callback
+// URLs, even when they resolve to internal addresses
+func (c *Client) SetAllowedHosts(hosts []string) {
+	c.allowedHosts = make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		c.allowedHosts[strings.ToLower(host)] = true
+	}
+}
+
+func (c *Client) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if host, _, err := net.SplitHostPort(addr); err != nil || !c.allowedHosts[strings.ToLower(host)] {
+		dialer.Control = checkAddress
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// checkAddress rejects connections to addresses that are not publicly routable
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body. Receivers verify it by computing
+// the HMAC-SHA256 of the raw body with the shared secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver POSTs body to url once. The body is signed when secret is not empty.
+// It returns the response status code (0 if no response was received) and an
+// error unless the receiver answered with a 2xx status.
+func (c *Client) Deliver(ctx context.Context, url, secret string, jobID int64, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claude-code-pull-worker")
+	req.Header.Set(JobIDHeader, fmt.Sprintf("%d", jobID))
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send callback: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("callback returned unexpected status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
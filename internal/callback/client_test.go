@@ -0,0 +1,97 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDeliverRejectsInternalAddresses(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	port := mustURL(t, server.URL).Port()
+	for _, target := range []string{
+		server.URL,
+		"http://localhost:" + port,
+		"http://[::1]:" + port,
+		"http://10.0.0.1:" + port,
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0:" + port,
+	} {
+		t.Run(target, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			status, err := NewClient(time.Second).Deliver(ctx, target, "", 1, []byte(`{}`))
+			if !errors.Is(err, ErrForbiddenAddress) {
+				t.Fatalf("Deliver(%s) error = %v, want ErrForbiddenAddress", target, err)
+			}
+			if status != 0 {
+				t.Errorf("status = %d, want 0", status)
+			}
+		})
+	}
+	if called {
+		t.Error("callback reached the internal server")
+	}
+}
+
+func TestDeliverRejectsRedirectToInternalAddress(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer internal.Close()
+
+	client := NewClient(time.Second)
+	client.SetAllowedHosts([]string{"localhost"})
+	redirect := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+
+	// The redirect server is allowed by name, the address it redirects to is not
+	target := "http://localhost:" + mustURL(t, redirect.URL).Port()
+	if _, err := client.Deliver(context.Background(), target, "", 1, []byte(`{}`)); !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("Deliver error = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestDeliverToAllowedHost(t *testing.T) {
+	var gotSignature, gotJobID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotJobID = r.Header.Get(JobIDHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second)
+	client.SetAllowedHosts([]string{"127.0.0.1"})
+	body := []byte(`{"success":true}`)
+	status, err := client.Deliver(context.Background(), server.URL, "secret", 42, body)
+	if err != nil {
+		t.Fatalf("Deliver error = %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", status, http.StatusNoContent)
+	}
+	if gotSignature != Sign("secret", body) {
+		t.Errorf("signature = %q, want %q", gotSignature, Sign("secret", body))
+	}
+	if gotJobID != "42" {
+		t.Errorf("job ID header = %q, want 42", gotJobID)
+	}
+}
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,6 +14,33 @@ type Config struct {
 	Port              string
 	APIKey            string
 	ClaudeTimeout     time.Duration
+	WorkerPoolSize    int
+
+	// Discord bot settings; the interactions endpoint is enabled when DiscordPublicKey is set
+	DiscordPublicKey     string
+	DiscordBotToken      string
+	DiscordApplicationID string
+	DiscordAPIBaseURL    string
+
+	// Slack app settings; the Slack endpoint is enabled when SlackSigningSecret is set
+	SlackSigningSecret string
+	SlackBotToken      string
+	SlackAPIBaseURL    string
+
+	// GitHub token used to comment job results; integrations are configured per webhook
+	GitHubToken      string
+	GitHubAPIBaseURL string
+
+	// Hosts that job callbacks may reach even if they resolve to loopback or private addresses
+	CallbackAllowedHosts []string
+
+	// Spans are exported over OTLP only when an OTLP endpoint is configured
+	TracingEnabled bool
+
+	// LogLevel is one of debug, info, warn or error; prompts are only logged at debug
+	LogLevel string
+	// LogFormat is json or text
+	LogFormat string
 }
 
 func Load() (*Config, error) {
@@ -29,10 +58,56 @@ func Load() (*Config, error) {
 		claudeTimeout = 1 * time.Hour
 	}
 
+	// Number of queue workers processing jobs in parallel
+	workerPoolSize, err := strconv.Atoi(os.Getenv("WORKER_POOL_SIZE"))
+	if err != nil || workerPoolSize < 1 {
+		workerPoolSize = 1
+	}
+
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	logFormat := os.Getenv("LOG_FORMAT")
+	if logFormat == "" {
+		logFormat = "json"
+	}
+
 	return &Config{
 		DiscordWebhookURL: os.Getenv("DISCORD_WEBHOOK_URL"),
 		Port:              os.Getenv("PORT"),
 		APIKey:            os.Getenv("API_KEY"),
 		ClaudeTimeout:     claudeTimeout,
+		WorkerPoolSize:    workerPoolSize,
+
+		DiscordPublicKey:     os.Getenv("DISCORD_PUBLIC_KEY"),
+		DiscordBotToken:      os.Getenv("DISCORD_BOT_TOKEN"),
+		DiscordApplicationID: os.Getenv("DISCORD_APPLICATION_ID"),
+		DiscordAPIBaseURL:    os.Getenv("DISCORD_API_BASE_URL"),
+
+		SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		SlackBotToken:      os.Getenv("SLACK_BOT_TOKEN"),
+		SlackAPIBaseURL:    os.Getenv("SLACK_API_BASE_URL"),
+
+		GitHubToken:      os.Getenv("GITHUB_TOKEN"),
+		GitHubAPIBaseURL: os.Getenv("GITHUB_API_BASE_URL"),
+
+		CallbackAllowedHosts: splitList(os.Getenv("CALLBACK_ALLOWED_HOSTS")),
+
+		TracingEnabled: os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "",
+
+		LogLevel:  logLevel,
+		LogFormat: logFormat,
 	}, nil
-}
\ No newline at end of file
+}
+
+// splitList splits a comma-separated environment variable, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -16,16 +17,19 @@ func New(dataSourceName string) (*DB, error) {
 		dataSourceName = "claude-code-pull-worker.db"
 	}
 
-	db, err := sql.Open("sqlite3", dataSourceName)
+	// Enable foreign key constraints. A PRAGMA would only reach one connection
+	// of the pool, the DSN parameter applies to every connection it opens.
+	// Transactions take the write lock when they begin, so two of them that
+	// read before writing wait for each other instead of failing as busy.
+	sep := "?"
+	if strings.Contains(dataSourceName, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite3", dataSourceName+sep+"_foreign_keys=on&_txlock=immediate")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Enable foreign key constraints
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
-	}
-
 	// Note: Migrations are now handled by sqlite3def via make migrate command
 	// Run `make migrate` to apply schema changes
 
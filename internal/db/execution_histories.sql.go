@@ -23,19 +23,31 @@ func (q *Queries) CountExecutionHistoriesByWebhook(ctx context.Context, webhookI
 }
 
 const createExecutionHistory = `-- name: CreateExecutionHistory :one
-INSERT INTO execution_histories (webhook_id, api_key_id, prompt, response, error, success, execution_time_ms)
-VALUES (?, ?, ?, ?, ?, ?, ?)
-RETURNING id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at
+INSERT INTO execution_histories (
+    webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, session_id,
+    input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens,
+    total_cost_usd, num_turns, duration_ms
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms
 `
 
 type CreateExecutionHistoryParams struct {
-	WebhookID       string         `json:"webhook_id"`
-	ApiKeyID        sql.NullInt64  `json:"api_key_id"`
-	Prompt          string         `json:"prompt"`
-	Response        sql.NullString `json:"response"`
-	Error           sql.NullString `json:"error"`
-	Success         bool           `json:"success"`
-	ExecutionTimeMs sql.NullInt64  `json:"execution_time_ms"`
+	WebhookID                string         `json:"webhook_id"`
+	ApiKeyID                 sql.NullInt64  `json:"api_key_id"`
+	Prompt                   string         `json:"prompt"`
+	Response                 sql.NullString `json:"response"`
+	Error                    sql.NullString `json:"error"`
+	Success                  bool           `json:"success"`
+	ExecutionTimeMs          sql.NullInt64  `json:"execution_time_ms"`
+	SessionID                sql.NullString `json:"session_id"`
+	InputTokens              int64          `json:"input_tokens"`
+	OutputTokens             int64          `json:"output_tokens"`
+	CacheReadInputTokens     int64          `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64          `json:"cache_creation_input_tokens"`
+	TotalCostUsd             float64        `json:"total_cost_usd"`
+	NumTurns                 int64          `json:"num_turns"`
+	DurationMs               int64          `json:"duration_ms"`
 }
 
 func (q *Queries) CreateExecutionHistory(ctx context.Context, arg CreateExecutionHistoryParams) (ExecutionHistory, error) {
@@ -47,6 +59,14 @@ func (q *Queries) CreateExecutionHistory(ctx context.Context, arg CreateExecutio
 		arg.Error,
 		arg.Success,
 		arg.ExecutionTimeMs,
+		arg.SessionID,
+		arg.InputTokens,
+		arg.OutputTokens,
+		arg.CacheReadInputTokens,
+		arg.CacheCreationInputTokens,
+		arg.TotalCostUsd,
+		arg.NumTurns,
+		arg.DurationMs,
 	)
 	var i ExecutionHistory
 	err := row.Scan(
@@ -59,12 +79,20 @@ func (q *Queries) CreateExecutionHistory(ctx context.Context, arg CreateExecutio
 		&i.Success,
 		&i.ExecutionTimeMs,
 		&i.CreatedAt,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
 	)
 	return i, err
 }
 
 const getExecutionHistory = `-- name: GetExecutionHistory :one
-SELECT id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at FROM execution_histories WHERE id = ?
+SELECT id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms FROM execution_histories WHERE id = ?
 `
 
 func (q *Queries) GetExecutionHistory(ctx context.Context, id int64) (ExecutionHistory, error) {
@@ -80,6 +108,14 @@ func (q *Queries) GetExecutionHistory(ctx context.Context, id int64) (ExecutionH
 		&i.Success,
 		&i.ExecutionTimeMs,
 		&i.CreatedAt,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
 	)
 	return i, err
 }
@@ -112,7 +148,7 @@ func (q *Queries) GetExecutionStats(ctx context.Context, arg GetExecutionStatsPa
 }
 
 const getLastExecution = `-- name: GetLastExecution :one
-SELECT id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at FROM execution_histories 
+SELECT id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms FROM execution_histories 
 WHERE webhook_id = ? AND success = 1
 ORDER BY created_at DESC
 LIMIT 1
@@ -131,12 +167,20 @@ func (q *Queries) GetLastExecution(ctx context.Context, webhookID string) (Execu
 		&i.Success,
 		&i.ExecutionTimeMs,
 		&i.CreatedAt,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
 	)
 	return i, err
 }
 
 const listExecutionHistoriesByWebhook = `-- name: ListExecutionHistoriesByWebhook :many
-SELECT id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at FROM execution_histories 
+SELECT id, webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, created_at, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms FROM execution_histories 
 WHERE webhook_id = ?
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?
@@ -167,6 +211,14 @@ func (q *Queries) ListExecutionHistoriesByWebhook(ctx context.Context, arg ListE
 			&i.Success,
 			&i.ExecutionTimeMs,
 			&i.CreatedAt,
+			&i.SessionID,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.CacheReadInputTokens,
+			&i.CacheCreationInputTokens,
+			&i.TotalCostUsd,
+			&i.NumTurns,
+			&i.DurationMs,
 		); err != nil {
 			return nil, err
 		}
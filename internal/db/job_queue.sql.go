@@ -8,27 +8,178 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
-const completeJob = `-- name: CompleteJob :exec
+const addJobUsage = `-- name: AddJobUsage :exec
+UPDATE job_queue
+SET
+    input_tokens = input_tokens + ?,
+    output_tokens = output_tokens + ?,
+    cache_read_input_tokens = cache_read_input_tokens + ?,
+    cache_creation_input_tokens = cache_creation_input_tokens + ?,
+    total_cost_usd = total_cost_usd + ?,
+    num_turns = num_turns + ?,
+    duration_ms = duration_ms + ?
+WHERE id = ?
+`
+
+type AddJobUsageParams struct {
+	InputTokens              int64   `json:"input_tokens"`
+	OutputTokens             int64   `json:"output_tokens"`
+	CacheReadInputTokens     int64   `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64   `json:"cache_creation_input_tokens"`
+	TotalCostUsd             float64 `json:"total_cost_usd"`
+	NumTurns                 int64   `json:"num_turns"`
+	DurationMs               int64   `json:"duration_ms"`
+	ID                       int64   `json:"id"`
+}
+
+// Adds what an attempt consumed; retried jobs accumulate the usage of every attempt
+func (q *Queries) AddJobUsage(ctx context.Context, arg AddJobUsageParams) error {
+	_, err := q.db.ExecContext(ctx, addJobUsage,
+		arg.InputTokens,
+		arg.OutputTokens,
+		arg.CacheReadInputTokens,
+		arg.CacheCreationInputTokens,
+		arg.TotalCostUsd,
+		arg.NumTurns,
+		arg.DurationMs,
+		arg.ID,
+	)
+	return err
+}
+
+const cancelJob = `-- name: CancelJob :one
+UPDATE job_queue
+SET 
+    job_status = 'cancelled',
+    completed_at = CURRENT_TIMESTAMP,
+    visibility_timeout = NULL
+WHERE id = ? AND job_status IN ('pending', 'processing')
+RETURNING id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id
+`
+
+func (q *Queries) CancelJob(ctx context.Context, id int64) (JobQueue, error) {
+	row := q.db.QueryRowContext(ctx, cancelJob, id)
+	var i JobQueue
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.ApiKeyID,
+		&i.Prompt,
+		&i.JobStatus,
+		&i.Priority,
+		&i.RetryCount,
+		&i.MaxRetries,
+		&i.WorkerID,
+		&i.VisibilityTimeout,
+		&i.ErrorMessage,
+		&i.Response,
+		&i.ExecutionTimeMs,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.WorkingDir,
+		&i.MaxThinkingTokens,
+		&i.MaxTurns,
+		&i.CustomSystemPrompt,
+		&i.AppendSystemPrompt,
+		&i.AllowedTools,
+		&i.DisallowedTools,
+		&i.PermissionMode,
+		&i.PermissionPromptToolName,
+		&i.Model,
+		&i.FallbackModel,
+		&i.McpServers,
+		&i.EnableContinue,
+		&i.ContinueMinutes,
+		&i.NextAttemptAt,
+		&i.CallbackUrl,
+		&i.CallbackSecret,
+		&i.PromptTemplate,
+		&i.PromptVariables,
+		&i.ResumeSessionID,
+		&i.Thread,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
+		&i.TraceContext,
+		&i.RequestID,
+	)
+	return i, err
+}
+
+const completeJob = `-- name: CompleteJob :execrows
 UPDATE job_queue
 SET 
     job_status = 'completed',
     completed_at = CURRENT_TIMESTAMP,
     response = ?,
-    execution_time_ms = ?
-WHERE id = ? AND job_status = 'processing'
+    execution_time_ms = ?,
+    session_id = ?
+WHERE id = ? AND worker_id = ? AND job_status = 'processing'
 `
 
 type CompleteJobParams struct {
 	Response        sql.NullString `json:"response"`
 	ExecutionTimeMs sql.NullInt64  `json:"execution_time_ms"`
+	SessionID       sql.NullString `json:"session_id"`
 	ID              int64          `json:"id"`
+	WorkerID        sql.NullString `json:"worker_id"`
 }
 
-func (q *Queries) CompleteJob(ctx context.Context, arg CompleteJobParams) error {
-	_, err := q.db.ExecContext(ctx, completeJob, arg.Response, arg.ExecutionTimeMs, arg.ID)
-	return err
+func (q *Queries) CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, completeJob,
+		arg.Response,
+		arg.ExecutionTimeMs,
+		arg.SessionID,
+		arg.ID,
+		arg.WorkerID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countJobsByStatus = `-- name: CountJobsByStatus :many
+SELECT job_status, COUNT(*) AS count
+FROM job_queue
+GROUP BY job_status
+`
+
+type CountJobsByStatusRow struct {
+	JobStatus string `json:"job_status"`
+	Count     int64  `json:"count"`
+}
+
+func (q *Queries) CountJobsByStatus(ctx context.Context) ([]CountJobsByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, countJobsByStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountJobsByStatusRow
+	for rows.Next() {
+		var i CountJobsByStatusRow
+		if err := rows.Scan(&i.JobStatus, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const dequeueJob = `-- name: DequeueJob :one
@@ -39,13 +190,27 @@ SET
     visibility_timeout = datetime('now', '+10 minutes'),
     worker_id = ?
 WHERE id = (
-    SELECT id FROM job_queue
-    WHERE job_status = 'pending'
-       OR (job_status = 'processing' AND visibility_timeout < CURRENT_TIMESTAMP)
-    ORDER BY priority DESC, created_at ASC
+    SELECT j.id FROM job_queue j
+    JOIN webhooks w ON w.id = j.webhook_id
+    WHERE ((j.job_status = 'pending'
+            AND (j.next_attempt_at IS NULL OR j.next_attempt_at <= CURRENT_TIMESTAMP))
+       OR (j.job_status = 'processing' AND j.visibility_timeout < CURRENT_TIMESTAMP))
+      AND (
+        SELECT COUNT(*) FROM job_queue r
+        WHERE r.webhook_id = j.webhook_id
+          AND r.job_status = 'processing'
+          AND r.visibility_timeout >= CURRENT_TIMESTAMP
+      ) < w.max_concurrency
+      AND (COALESCE(j.working_dir, '') = '' OR NOT EXISTS (
+        SELECT 1 FROM job_queue r
+        WHERE r.job_status = 'processing'
+          AND r.visibility_timeout >= CURRENT_TIMESTAMP
+          AND r.working_dir = j.working_dir
+      ))
+    ORDER BY j.priority DESC, j.created_at ASC
     LIMIT 1
 )
-RETURNING id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes
+RETURNING id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id
 `
 
 func (q *Queries) DequeueJob(ctx context.Context, workerID sql.NullString) (JobQueue, error) {
@@ -82,10 +247,64 @@ func (q *Queries) DequeueJob(ctx context.Context, workerID sql.NullString) (JobQ
 		&i.McpServers,
 		&i.EnableContinue,
 		&i.ContinueMinutes,
+		&i.NextAttemptAt,
+		&i.CallbackUrl,
+		&i.CallbackSecret,
+		&i.PromptTemplate,
+		&i.PromptVariables,
+		&i.ResumeSessionID,
+		&i.Thread,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
+		&i.TraceContext,
+		&i.RequestID,
 	)
 	return i, err
 }
 
+const discardDeadLetterJobs = `-- name: DiscardDeadLetterJobs :execrows
+DELETE FROM job_queue
+WHERE job_status = 'failed'
+  AND (? IS NULL OR webhook_id = ?)
+  AND (? IS NULL OR error_message LIKE '%' || ? || '%')
+`
+
+type DiscardDeadLetterJobsParams struct {
+	WebhookID     sql.NullString `json:"webhook_id"`
+	ErrorContains sql.NullString `json:"error_contains"`
+}
+
+func (q *Queries) DiscardDeadLetterJobs(ctx context.Context, arg DiscardDeadLetterJobsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, discardDeadLetterJobs,
+		arg.WebhookID,
+		arg.WebhookID,
+		arg.ErrorContains,
+		arg.ErrorContains,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const discardJob = `-- name: DiscardJob :execrows
+DELETE FROM job_queue WHERE id = ? AND job_status = 'failed'
+`
+
+func (q *Queries) DiscardJob(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, discardJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const enqueueJob = `-- name: EnqueueJob :one
 INSERT INTO job_queue (
     webhook_id,
@@ -105,10 +324,18 @@ INSERT INTO job_queue (
     fallback_model,
     mcp_servers,
     enable_continue,
-    continue_minutes
+    continue_minutes,
+    callback_url,
+    callback_secret,
+    prompt_template,
+    prompt_variables,
+    resume_session_id,
+    thread,
+    trace_context,
+    request_id
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
-) RETURNING id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id
 `
 
 type EnqueueJobParams struct {
@@ -130,6 +357,14 @@ type EnqueueJobParams struct {
 	McpServers               sql.NullString `json:"mcp_servers"`
 	EnableContinue           bool           `json:"enable_continue"`
 	ContinueMinutes          int64          `json:"continue_minutes"`
+	CallbackUrl              sql.NullString `json:"callback_url"`
+	CallbackSecret           sql.NullString `json:"callback_secret"`
+	PromptTemplate           sql.NullString `json:"prompt_template"`
+	PromptVariables          sql.NullString `json:"prompt_variables"`
+	ResumeSessionID          sql.NullString `json:"resume_session_id"`
+	Thread                   sql.NullString `json:"thread"`
+	TraceContext             sql.NullString `json:"trace_context"`
+	RequestID                sql.NullString `json:"request_id"`
 }
 
 func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (JobQueue, error) {
@@ -152,6 +387,14 @@ func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (JobQueu
 		arg.McpServers,
 		arg.EnableContinue,
 		arg.ContinueMinutes,
+		arg.CallbackUrl,
+		arg.CallbackSecret,
+		arg.PromptTemplate,
+		arg.PromptVariables,
+		arg.ResumeSessionID,
+		arg.Thread,
+		arg.TraceContext,
+		arg.RequestID,
 	)
 	var i JobQueue
 	err := row.Scan(
@@ -185,36 +428,87 @@ func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (JobQueu
 		&i.McpServers,
 		&i.EnableContinue,
 		&i.ContinueMinutes,
+		&i.NextAttemptAt,
+		&i.CallbackUrl,
+		&i.CallbackSecret,
+		&i.PromptTemplate,
+		&i.PromptVariables,
+		&i.ResumeSessionID,
+		&i.Thread,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
+		&i.TraceContext,
+		&i.RequestID,
 	)
 	return i, err
 }
 
-const failJob = `-- name: FailJob :exec
+const extendJobVisibility = `-- name: ExtendJobVisibility :execrows
+UPDATE job_queue
+SET visibility_timeout = datetime('now', '+10 minutes')
+WHERE id = ? AND worker_id = ? AND job_status = 'processing'
+`
+
+type ExtendJobVisibilityParams struct {
+	ID       int64          `json:"id"`
+	WorkerID sql.NullString `json:"worker_id"`
+}
+
+func (q *Queries) ExtendJobVisibility(ctx context.Context, arg ExtendJobVisibilityParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, extendJobVisibility, arg.ID, arg.WorkerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failJob = `-- name: FailJob :execrows
 UPDATE job_queue
 SET 
     job_status = CASE 
-        WHEN retry_count >= max_retries THEN 'failed'
+        WHEN NOT ? OR retry_count >= max_retries THEN 'failed'
         ELSE 'pending'
     END,
+    next_attempt_at = datetime('now', printf('+%d seconds', ?)),
     retry_count = retry_count + 1,
     error_message = ?,
     visibility_timeout = NULL,
     worker_id = NULL
-WHERE id = ? AND job_status = 'processing'
+WHERE id = ? AND worker_id = ? AND job_status = 'processing'
 `
 
 type FailJobParams struct {
-	ErrorMessage sql.NullString `json:"error_message"`
-	ID           int64          `json:"id"`
+	Retryable      bool           `json:"retryable"`
+	BackoffSeconds int64          `json:"backoff_seconds"`
+	ErrorMessage   sql.NullString `json:"error_message"`
+	ID             int64          `json:"id"`
+	WorkerID       sql.NullString `json:"worker_id"`
 }
 
-func (q *Queries) FailJob(ctx context.Context, arg FailJobParams) error {
-	_, err := q.db.ExecContext(ctx, failJob, arg.ErrorMessage, arg.ID)
-	return err
+// Retryable failures go back to pending until max_retries is reached and
+// are not picked up again before the backoff delay has passed
+func (q *Queries) FailJob(ctx context.Context, arg FailJobParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failJob,
+		arg.Retryable,
+		arg.BackoffSeconds,
+		arg.ErrorMessage,
+		arg.ID,
+		arg.WorkerID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getJobStatus = `-- name: GetJobStatus :one
-SELECT id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes FROM job_queue WHERE id = ?
+SELECT id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id FROM job_queue WHERE id = ?
 `
 
 func (q *Queries) GetJobStatus(ctx context.Context, id int64) (JobQueue, error) {
@@ -251,12 +545,29 @@ func (q *Queries) GetJobStatus(ctx context.Context, id int64) (JobQueue, error)
 		&i.McpServers,
 		&i.EnableContinue,
 		&i.ContinueMinutes,
+		&i.NextAttemptAt,
+		&i.CallbackUrl,
+		&i.CallbackSecret,
+		&i.PromptTemplate,
+		&i.PromptVariables,
+		&i.ResumeSessionID,
+		&i.Thread,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
+		&i.TraceContext,
+		&i.RequestID,
 	)
 	return i, err
 }
 
 const getJobsByWebhook = `-- name: GetJobsByWebhook :many
-SELECT id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes FROM job_queue
+SELECT id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id FROM job_queue
 WHERE webhook_id = ?
 ORDER BY created_at DESC
 LIMIT ?
@@ -307,6 +618,23 @@ func (q *Queries) GetJobsByWebhook(ctx context.Context, arg GetJobsByWebhookPara
 			&i.McpServers,
 			&i.EnableContinue,
 			&i.ContinueMinutes,
+			&i.NextAttemptAt,
+			&i.CallbackUrl,
+			&i.CallbackSecret,
+			&i.PromptTemplate,
+			&i.PromptVariables,
+			&i.ResumeSessionID,
+			&i.Thread,
+			&i.SessionID,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.CacheReadInputTokens,
+			&i.CacheCreationInputTokens,
+			&i.TotalCostUsd,
+			&i.NumTurns,
+			&i.DurationMs,
+			&i.TraceContext,
+			&i.RequestID,
 		); err != nil {
 			return nil, err
 		}
@@ -333,7 +661,7 @@ func (q *Queries) GetPendingJobCount(ctx context.Context) (int64, error) {
 }
 
 const getRecentJobs = `-- name: GetRecentJobs :many
-SELECT id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes FROM job_queue
+SELECT id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id FROM job_queue
 ORDER BY created_at DESC
 LIMIT ?
 `
@@ -378,6 +706,207 @@ func (q *Queries) GetRecentJobs(ctx context.Context, limit int64) ([]JobQueue, e
 			&i.McpServers,
 			&i.EnableContinue,
 			&i.ContinueMinutes,
+			&i.NextAttemptAt,
+			&i.CallbackUrl,
+			&i.CallbackSecret,
+			&i.PromptTemplate,
+			&i.PromptVariables,
+			&i.ResumeSessionID,
+			&i.Thread,
+			&i.SessionID,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.CacheReadInputTokens,
+			&i.CacheCreationInputTokens,
+			&i.TotalCostUsd,
+			&i.NumTurns,
+			&i.DurationMs,
+			&i.TraceContext,
+			&i.RequestID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadSessionID = `-- name: GetThreadSessionID :one
+SELECT session_id FROM job_queue
+WHERE webhook_id = ? AND thread = ? AND session_id IS NOT NULL
+ORDER BY completed_at DESC, id DESC
+LIMIT 1
+`
+
+type GetThreadSessionIDParams struct {
+	WebhookID string         `json:"webhook_id"`
+	Thread    sql.NullString `json:"thread"`
+}
+
+// Returns the session the latest completed job of a named thread ended in
+func (q *Queries) GetThreadSessionID(ctx context.Context, arg GetThreadSessionIDParams) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, getThreadSessionID, arg.WebhookID, arg.Thread)
+	var session_id sql.NullString
+	err := row.Scan(&session_id)
+	return session_id, err
+}
+
+const hasWebhookSession = `-- name: HasWebhookSession :one
+SELECT EXISTS (
+    SELECT 1 FROM job_queue WHERE webhook_id = ? AND session_id = ?
+)
+`
+
+type HasWebhookSessionParams struct {
+	WebhookID string         `json:"webhook_id"`
+	SessionID sql.NullString `json:"session_id"`
+}
+
+func (q *Queries) HasWebhookSession(ctx context.Context, arg HasWebhookSessionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasWebhookSession, arg.WebhookID, arg.SessionID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listDeadLetterJobs = `-- name: ListDeadLetterJobs :many
+SELECT j.id, j.webhook_id, j.api_key_id, j.prompt, j.job_status, j.priority, j.retry_count, j.max_retries, j.worker_id, j.visibility_timeout, j.error_message, j.response, j.execution_time_ms, j.created_at, j.started_at, j.completed_at, j.working_dir, j.max_thinking_tokens, j.max_turns, j.custom_system_prompt, j.append_system_prompt, j.allowed_tools, j.disallowed_tools, j.permission_mode, j.permission_prompt_tool_name, j.model, j.fallback_model, j.mcp_servers, j.enable_continue, j.continue_minutes, j.next_attempt_at, j.callback_url, j.callback_secret, j.prompt_template, j.prompt_variables, j.resume_session_id, j.thread, j.session_id, j.input_tokens, j.output_tokens, j.cache_read_input_tokens, j.cache_creation_input_tokens, j.total_cost_usd, j.num_turns, j.duration_ms, j.trace_context, j.request_id, w.name AS webhook_name
+FROM job_queue j
+JOIN webhooks w ON w.id = j.webhook_id
+WHERE j.job_status = 'failed'
+  AND (? IS NULL OR j.webhook_id = ?)
+  AND (? IS NULL OR j.error_message LIKE '%' || ? || '%')
+ORDER BY j.id DESC
+LIMIT ?
+`
+
+type ListDeadLetterJobsParams struct {
+	WebhookID     sql.NullString `json:"webhook_id"`
+	ErrorContains sql.NullString `json:"error_contains"`
+	Limit         int64          `json:"limit"`
+}
+
+type ListDeadLetterJobsRow struct {
+	ID                       int64          `json:"id"`
+	WebhookID                string         `json:"webhook_id"`
+	ApiKeyID                 sql.NullInt64  `json:"api_key_id"`
+	Prompt                   string         `json:"prompt"`
+	JobStatus                string         `json:"job_status"`
+	Priority                 int64          `json:"priority"`
+	RetryCount               int64          `json:"retry_count"`
+	MaxRetries               int64          `json:"max_retries"`
+	WorkerID                 sql.NullString `json:"worker_id"`
+	VisibilityTimeout        sql.NullTime   `json:"visibility_timeout"`
+	ErrorMessage             sql.NullString `json:"error_message"`
+	Response                 sql.NullString `json:"response"`
+	ExecutionTimeMs          sql.NullInt64  `json:"execution_time_ms"`
+	CreatedAt                time.Time      `json:"created_at"`
+	StartedAt                sql.NullTime   `json:"started_at"`
+	CompletedAt              sql.NullTime   `json:"completed_at"`
+	WorkingDir               sql.NullString `json:"working_dir"`
+	MaxThinkingTokens        sql.NullInt64  `json:"max_thinking_tokens"`
+	MaxTurns                 sql.NullInt64  `json:"max_turns"`
+	CustomSystemPrompt       sql.NullString `json:"custom_system_prompt"`
+	AppendSystemPrompt       sql.NullString `json:"append_system_prompt"`
+	AllowedTools             sql.NullString `json:"allowed_tools"`
+	DisallowedTools          sql.NullString `json:"disallowed_tools"`
+	PermissionMode           sql.NullString `json:"permission_mode"`
+	PermissionPromptToolName sql.NullString `json:"permission_prompt_tool_name"`
+	Model                    sql.NullString `json:"model"`
+	FallbackModel            sql.NullString `json:"fallback_model"`
+	McpServers               sql.NullString `json:"mcp_servers"`
+	EnableContinue           bool           `json:"enable_continue"`
+	ContinueMinutes          int64          `json:"continue_minutes"`
+	NextAttemptAt            sql.NullTime   `json:"next_attempt_at"`
+	CallbackUrl              sql.NullString `json:"callback_url"`
+	CallbackSecret           sql.NullString `json:"callback_secret"`
+	PromptTemplate           sql.NullString `json:"prompt_template"`
+	PromptVariables          sql.NullString `json:"prompt_variables"`
+	ResumeSessionID          sql.NullString `json:"resume_session_id"`
+	Thread                   sql.NullString `json:"thread"`
+	SessionID                sql.NullString `json:"session_id"`
+	InputTokens              int64          `json:"input_tokens"`
+	OutputTokens             int64          `json:"output_tokens"`
+	CacheReadInputTokens     int64          `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64          `json:"cache_creation_input_tokens"`
+	TotalCostUsd             float64        `json:"total_cost_usd"`
+	NumTurns                 int64          `json:"num_turns"`
+	DurationMs               int64          `json:"duration_ms"`
+	TraceContext             sql.NullString `json:"trace_context"`
+	RequestID                sql.NullString `json:"request_id"`
+	WebhookName              string         `json:"webhook_name"`
+}
+
+func (q *Queries) ListDeadLetterJobs(ctx context.Context, arg ListDeadLetterJobsParams) ([]ListDeadLetterJobsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDeadLetterJobs,
+		arg.WebhookID,
+		arg.WebhookID,
+		arg.ErrorContains,
+		arg.ErrorContains,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeadLetterJobsRow{}
+	for rows.Next() {
+		var i ListDeadLetterJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.ApiKeyID,
+			&i.Prompt,
+			&i.JobStatus,
+			&i.Priority,
+			&i.RetryCount,
+			&i.MaxRetries,
+			&i.WorkerID,
+			&i.VisibilityTimeout,
+			&i.ErrorMessage,
+			&i.Response,
+			&i.ExecutionTimeMs,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.CompletedAt,
+			&i.WorkingDir,
+			&i.MaxThinkingTokens,
+			&i.MaxTurns,
+			&i.CustomSystemPrompt,
+			&i.AppendSystemPrompt,
+			&i.AllowedTools,
+			&i.DisallowedTools,
+			&i.PermissionMode,
+			&i.PermissionPromptToolName,
+			&i.Model,
+			&i.FallbackModel,
+			&i.McpServers,
+			&i.EnableContinue,
+			&i.ContinueMinutes,
+			&i.NextAttemptAt,
+			&i.CallbackUrl,
+			&i.CallbackSecret,
+			&i.PromptTemplate,
+			&i.PromptVariables,
+			&i.ResumeSessionID,
+			&i.Thread,
+			&i.SessionID,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.CacheReadInputTokens,
+			&i.CacheCreationInputTokens,
+			&i.TotalCostUsd,
+			&i.NumTurns,
+			&i.DurationMs,
+			&i.TraceContext,
+			&i.RequestID,
+			&i.WebhookName,
 		); err != nil {
 			return nil, err
 		}
@@ -392,6 +921,106 @@ func (q *Queries) GetRecentJobs(ctx context.Context, limit int64) ([]JobQueue, e
 	return items, nil
 }
 
+const requeueDeadLetterJobs = `-- name: RequeueDeadLetterJobs :execrows
+UPDATE job_queue
+SET 
+    job_status = 'pending',
+    retry_count = 0,
+    next_attempt_at = NULL,
+    error_message = NULL,
+    worker_id = NULL,
+    started_at = NULL
+WHERE job_status = 'failed'
+  AND (? IS NULL OR webhook_id = ?)
+  AND (? IS NULL OR error_message LIKE '%' || ? || '%')
+`
+
+type RequeueDeadLetterJobsParams struct {
+	WebhookID     sql.NullString `json:"webhook_id"`
+	ErrorContains sql.NullString `json:"error_contains"`
+}
+
+func (q *Queries) RequeueDeadLetterJobs(ctx context.Context, arg RequeueDeadLetterJobsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, requeueDeadLetterJobs,
+		arg.WebhookID,
+		arg.WebhookID,
+		arg.ErrorContains,
+		arg.ErrorContains,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requeueJob = `-- name: RequeueJob :one
+UPDATE job_queue
+SET 
+    job_status = 'pending',
+    retry_count = 0,
+    next_attempt_at = NULL,
+    error_message = NULL,
+    worker_id = NULL,
+    started_at = NULL
+WHERE id = ? AND job_status = 'failed'
+RETURNING id, webhook_id, api_key_id, prompt, job_status, priority, retry_count, max_retries, worker_id, visibility_timeout, error_message, response, execution_time_ms, created_at, started_at, completed_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, enable_continue, continue_minutes, next_attempt_at, callback_url, callback_secret, prompt_template, prompt_variables, resume_session_id, thread, session_id, input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens, total_cost_usd, num_turns, duration_ms, trace_context, request_id
+`
+
+func (q *Queries) RequeueJob(ctx context.Context, id int64) (JobQueue, error) {
+	row := q.db.QueryRowContext(ctx, requeueJob, id)
+	var i JobQueue
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.ApiKeyID,
+		&i.Prompt,
+		&i.JobStatus,
+		&i.Priority,
+		&i.RetryCount,
+		&i.MaxRetries,
+		&i.WorkerID,
+		&i.VisibilityTimeout,
+		&i.ErrorMessage,
+		&i.Response,
+		&i.ExecutionTimeMs,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.WorkingDir,
+		&i.MaxThinkingTokens,
+		&i.MaxTurns,
+		&i.CustomSystemPrompt,
+		&i.AppendSystemPrompt,
+		&i.AllowedTools,
+		&i.DisallowedTools,
+		&i.PermissionMode,
+		&i.PermissionPromptToolName,
+		&i.Model,
+		&i.FallbackModel,
+		&i.McpServers,
+		&i.EnableContinue,
+		&i.ContinueMinutes,
+		&i.NextAttemptAt,
+		&i.CallbackUrl,
+		&i.CallbackSecret,
+		&i.PromptTemplate,
+		&i.PromptVariables,
+		&i.ResumeSessionID,
+		&i.Thread,
+		&i.SessionID,
+		&i.InputTokens,
+		&i.OutputTokens,
+		&i.CacheReadInputTokens,
+		&i.CacheCreationInputTokens,
+		&i.TotalCostUsd,
+		&i.NumTurns,
+		&i.DurationMs,
+		&i.TraceContext,
+		&i.RequestID,
+	)
+	return i, err
+}
+
 const resetStaleJobs = `-- name: ResetStaleJobs :exec
 UPDATE job_queue
 SET 
@@ -21,16 +21,66 @@ type ApiKey struct {
 	LastUsedAt  sql.NullTime   `json:"last_used_at"`
 }
 
+type CallbackDelivery struct {
+	ID           int64          `json:"id"`
+	JobID        int64          `json:"job_id"`
+	Url          string         `json:"url"`
+	Attempt      int64          `json:"attempt"`
+	StatusCode   sql.NullInt64  `json:"status_code"`
+	ErrorMessage sql.NullString `json:"error_message"`
+	Success      bool           `json:"success"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type DiscordChannel struct {
+	ChannelID string    `json:"channel_id"`
+	WebhookID string    `json:"webhook_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DiscordReply struct {
+	JobID     int64          `json:"job_id"`
+	ChannelID string         `json:"channel_id"`
+	MessageID sql.NullString `json:"message_id"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
 type ExecutionHistory struct {
-	ID              int64          `json:"id"`
-	WebhookID       string         `json:"webhook_id"`
-	ApiKeyID        sql.NullInt64  `json:"api_key_id"`
-	Prompt          string         `json:"prompt"`
-	Response        sql.NullString `json:"response"`
-	Error           sql.NullString `json:"error"`
-	Success         bool           `json:"success"`
-	ExecutionTimeMs sql.NullInt64  `json:"execution_time_ms"`
-	CreatedAt       time.Time      `json:"created_at"`
+	ID                       int64          `json:"id"`
+	WebhookID                string         `json:"webhook_id"`
+	ApiKeyID                 sql.NullInt64  `json:"api_key_id"`
+	Prompt                   string         `json:"prompt"`
+	Response                 sql.NullString `json:"response"`
+	Error                    sql.NullString `json:"error"`
+	Success                  bool           `json:"success"`
+	ExecutionTimeMs          sql.NullInt64  `json:"execution_time_ms"`
+	CreatedAt                time.Time      `json:"created_at"`
+	SessionID                sql.NullString `json:"session_id"`
+	InputTokens              int64          `json:"input_tokens"`
+	OutputTokens             int64          `json:"output_tokens"`
+	CacheReadInputTokens     int64          `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64          `json:"cache_creation_input_tokens"`
+	TotalCostUsd             float64        `json:"total_cost_usd"`
+	NumTurns                 int64          `json:"num_turns"`
+	DurationMs               int64          `json:"duration_ms"`
+}
+
+type GithubIntegration struct {
+	WebhookID           string    `json:"webhook_id"`
+	Secret              string    `json:"secret"`
+	Events              string    `json:"events"`
+	TriggerPhrase       string    `json:"trigger_phrase"`
+	PromptTemplate      string    `json:"prompt_template"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	AllowedAssociations string    `json:"allowed_associations"`
+}
+
+type GithubReply struct {
+	JobID       int64     `json:"job_id"`
+	Repository  string    `json:"repository"`
+	IssueNumber int64     `json:"issue_number"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type GlobalSetting struct {
@@ -39,6 +89,22 @@ type GlobalSetting struct {
 	UpdatedAt    time.Time   `json:"updated_at"`
 }
 
+type JobAttempt struct {
+	ID           int64     `json:"id"`
+	JobID        int64     `json:"job_id"`
+	ErrorMessage string    `json:"error_message"`
+	Retryable    bool      `json:"retryable"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type JobEvent struct {
+	ID        int64     `json:"id"`
+	JobID     int64     `json:"job_id"`
+	EventType string    `json:"event_type"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type JobQueue struct {
 	ID                       int64          `json:"id"`
 	WebhookID                string         `json:"webhook_id"`
@@ -70,6 +136,56 @@ type JobQueue struct {
 	McpServers               sql.NullString `json:"mcp_servers"`
 	EnableContinue           bool           `json:"enable_continue"`
 	ContinueMinutes          int64          `json:"continue_minutes"`
+	NextAttemptAt            sql.NullTime   `json:"next_attempt_at"`
+	CallbackUrl              sql.NullString `json:"callback_url"`
+	CallbackSecret           sql.NullString `json:"callback_secret"`
+	PromptTemplate           sql.NullString `json:"prompt_template"`
+	PromptVariables          sql.NullString `json:"prompt_variables"`
+	ResumeSessionID          sql.NullString `json:"resume_session_id"`
+	Thread                   sql.NullString `json:"thread"`
+	SessionID                sql.NullString `json:"session_id"`
+	InputTokens              int64          `json:"input_tokens"`
+	OutputTokens             int64          `json:"output_tokens"`
+	CacheReadInputTokens     int64          `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64          `json:"cache_creation_input_tokens"`
+	TotalCostUsd             float64        `json:"total_cost_usd"`
+	NumTurns                 int64          `json:"num_turns"`
+	DurationMs               int64          `json:"duration_ms"`
+	TraceContext             sql.NullString `json:"trace_context"`
+	RequestID                sql.NullString `json:"request_id"`
+}
+
+type JobUsage struct {
+	ID                       int64     `json:"id"`
+	JobID                    int64     `json:"job_id"`
+	WebhookID                string    `json:"webhook_id"`
+	InputTokens              int64     `json:"input_tokens"`
+	OutputTokens             int64     `json:"output_tokens"`
+	CacheReadInputTokens     int64     `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64     `json:"cache_creation_input_tokens"`
+	TotalCostUsd             float64   `json:"total_cost_usd"`
+	NumTurns                 int64     `json:"num_turns"`
+	DurationMs               int64     `json:"duration_ms"`
+	JobCreatedAt             time.Time `json:"job_created_at"`
+	CreatedAt                time.Time `json:"created_at"`
+}
+
+type NotificationDelivery struct {
+	ID            int64          `json:"id"`
+	JobID         int64          `json:"job_id"`
+	Notifier      string         `json:"notifier"`
+	Config        string         `json:"config"`
+	Payload       string         `json:"payload"`
+	Status        string         `json:"status"`
+	Attempts      int64          `json:"attempts"`
+	MaxAttempts   int64          `json:"max_attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DeliveredAt   sql.NullTime   `json:"delivered_at"`
+	TraceContext  sql.NullString `json:"trace_context"`
+	RequestID     sql.NullString `json:"request_id"`
+	SentMessages  int64          `json:"sent_messages"`
 }
 
 type SecurityAuditLog struct {
@@ -84,26 +200,43 @@ type SecurityAuditLog struct {
 	CreatedAt      time.Time      `json:"created_at"`
 }
 
+type SlackReply struct {
+	JobID       int64          `json:"job_id"`
+	ResponseUrl sql.NullString `json:"response_url"`
+	ChannelID   sql.NullString `json:"channel_id"`
+	ThreadTs    sql.NullString `json:"thread_ts"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
 type Webhook struct {
-	ID                       string         `json:"id"`
-	Name                     string         `json:"name"`
-	Description              sql.NullString `json:"description"`
-	IsActive                 bool           `json:"is_active"`
-	CreatedAt                time.Time      `json:"created_at"`
-	UpdatedAt                time.Time      `json:"updated_at"`
-	WorkingDir               sql.NullString `json:"working_dir"`
-	MaxThinkingTokens        sql.NullInt64  `json:"max_thinking_tokens"`
-	MaxTurns                 sql.NullInt64  `json:"max_turns"`
-	CustomSystemPrompt       sql.NullString `json:"custom_system_prompt"`
-	AppendSystemPrompt       sql.NullString `json:"append_system_prompt"`
-	AllowedTools             sql.NullString `json:"allowed_tools"`
-	DisallowedTools          sql.NullString `json:"disallowed_tools"`
-	PermissionMode           sql.NullString `json:"permission_mode"`
-	PermissionPromptToolName sql.NullString `json:"permission_prompt_tool_name"`
-	Model                    sql.NullString `json:"model"`
-	FallbackModel            sql.NullString `json:"fallback_model"`
-	McpServers               sql.NullString `json:"mcp_servers"`
-	NotificationConfig       interface{}    `json:"notification_config"`
-	EnableContinue           bool           `json:"enable_continue"`
-	ContinueMinutes          int64          `json:"continue_minutes"`
+	ID                       string          `json:"id"`
+	Name                     string          `json:"name"`
+	Description              sql.NullString  `json:"description"`
+	IsActive                 bool            `json:"is_active"`
+	CreatedAt                time.Time       `json:"created_at"`
+	UpdatedAt                time.Time       `json:"updated_at"`
+	WorkingDir               sql.NullString  `json:"working_dir"`
+	MaxThinkingTokens        sql.NullInt64   `json:"max_thinking_tokens"`
+	MaxTurns                 sql.NullInt64   `json:"max_turns"`
+	CustomSystemPrompt       sql.NullString  `json:"custom_system_prompt"`
+	AppendSystemPrompt       sql.NullString  `json:"append_system_prompt"`
+	AllowedTools             sql.NullString  `json:"allowed_tools"`
+	DisallowedTools          sql.NullString  `json:"disallowed_tools"`
+	PermissionMode           sql.NullString  `json:"permission_mode"`
+	PermissionPromptToolName sql.NullString  `json:"permission_prompt_tool_name"`
+	Model                    sql.NullString  `json:"model"`
+	FallbackModel            sql.NullString  `json:"fallback_model"`
+	McpServers               sql.NullString  `json:"mcp_servers"`
+	NotificationConfig       interface{}     `json:"notification_config"`
+	EnableContinue           bool            `json:"enable_continue"`
+	ContinueMinutes          int64           `json:"continue_minutes"`
+	MaxConcurrency           int64           `json:"max_concurrency"`
+	RetryBackoffBaseSeconds  int64           `json:"retry_backoff_base_seconds"`
+	RetryBackoffFactor       float64         `json:"retry_backoff_factor"`
+	RetryBackoffJitter       float64         `json:"retry_backoff_jitter"`
+	RetryBackoffMaxSeconds   int64           `json:"retry_backoff_max_seconds"`
+	PromptTemplate           sql.NullString  `json:"prompt_template"`
+	OverridePolicy           sql.NullString  `json:"override_policy"`
+	DailyBudgetUsd           sql.NullFloat64 `json:"daily_budget_usd"`
+	MonthlyBudgetUsd         sql.NullFloat64 `json:"monthly_budget_usd"`
 }
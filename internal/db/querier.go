@@ -10,43 +10,85 @@ import (
 )
 
 type Querier interface {
-	CompleteJob(ctx context.Context, arg CompleteJobParams) error
+	AddJobUsage(ctx context.Context, arg AddJobUsageParams) error
+	CancelJob(ctx context.Context, id int64) (JobQueue, error)
+	ClaimNotificationDelivery(ctx context.Context) (NotificationDelivery, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error)
+	CompleteNotificationDelivery(ctx context.Context, id int64) error
 	CountExecutionHistoriesByWebhook(ctx context.Context, webhookID string) (int64, error)
+	CountJobsByStatus(ctx context.Context) ([]CountJobsByStatusRow, error)
 	CountSecurityAuditEvents(ctx context.Context, arg CountSecurityAuditEventsParams) (int64, error)
 	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateCallbackDelivery(ctx context.Context, arg CreateCallbackDeliveryParams) error
+	CreateDiscordChannel(ctx context.Context, arg CreateDiscordChannelParams) error
+	CreateDiscordReply(ctx context.Context, arg CreateDiscordReplyParams) error
 	CreateExecutionHistory(ctx context.Context, arg CreateExecutionHistoryParams) (ExecutionHistory, error)
+	CreateGitHubReply(ctx context.Context, arg CreateGitHubReplyParams) error
+	CreateJobAttempt(ctx context.Context, arg CreateJobAttemptParams) error
+	CreateJobEvent(ctx context.Context, arg CreateJobEventParams) error
+	CreateJobUsage(ctx context.Context, arg CreateJobUsageParams) error
+	CreateNotificationDelivery(ctx context.Context, arg CreateNotificationDeliveryParams) error
+	CreateSlackReply(ctx context.Context, arg CreateSlackReplyParams) error
 	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
 	DeleteAPIKey(ctx context.Context, id int64) error
+	DeleteDiscordChannels(ctx context.Context, webhookID string) error
+	DeleteGitHubIntegration(ctx context.Context, webhookID string) error
 	DeleteWebhook(ctx context.Context, id string) error
 	DequeueJob(ctx context.Context, workerID sql.NullString) (JobQueue, error)
+	DiscardDeadLetterJobs(ctx context.Context, arg DiscardDeadLetterJobsParams) (int64, error)
+	DiscardJob(ctx context.Context, id int64) (int64, error)
 	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (JobQueue, error)
-	FailJob(ctx context.Context, arg FailJobParams) error
+	ExtendJobVisibility(ctx context.Context, arg ExtendJobVisibilityParams) (int64, error)
+	FailJob(ctx context.Context, arg FailJobParams) (int64, error)
+	FailNotificationDelivery(ctx context.Context, arg FailNotificationDeliveryParams) error
 	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
 	GetAPIKeyWithWebhook(ctx context.Context, keyHash string) (GetAPIKeyWithWebhookRow, error)
 	GetAPIKeysForWebhook(ctx context.Context, webhookID string) ([]ApiKey, error)
+	GetDiscordReply(ctx context.Context, jobID int64) (DiscordReply, error)
 	GetExecutionHistory(ctx context.Context, id int64) (ExecutionHistory, error)
 	GetExecutionStats(ctx context.Context, arg GetExecutionStatsParams) (GetExecutionStatsRow, error)
+	GetGitHubIntegration(ctx context.Context, webhookID string) (GithubIntegration, error)
+	GetGitHubReply(ctx context.Context, jobID int64) (GithubReply, error)
 	GetGlobalSetting(ctx context.Context, settingKey string) (interface{}, error)
 	GetJobStatus(ctx context.Context, id int64) (JobQueue, error)
 	GetJobsByWebhook(ctx context.Context, arg GetJobsByWebhookParams) ([]JobQueue, error)
 	GetLastExecution(ctx context.Context, webhookID string) (ExecutionHistory, error)
+	GetNotificationDelivery(ctx context.Context, id int64) (NotificationDelivery, error)
 	GetPendingJobCount(ctx context.Context) (int64, error)
 	GetRecentJobs(ctx context.Context, limit int64) ([]JobQueue, error)
 	GetRecentSecurityAuditLogs(ctx context.Context, limit int64) ([]SecurityAuditLog, error)
 	GetSecurityAuditLogs(ctx context.Context, arg GetSecurityAuditLogsParams) ([]SecurityAuditLog, error)
 	GetSecurityAuditLogsByIP(ctx context.Context, arg GetSecurityAuditLogsByIPParams) ([]SecurityAuditLog, error)
 	GetSecurityAuditLogsByType(ctx context.Context, arg GetSecurityAuditLogsByTypeParams) ([]SecurityAuditLog, error)
+	GetSlackReply(ctx context.Context, jobID int64) (SlackReply, error)
+	GetThreadSessionID(ctx context.Context, arg GetThreadSessionIDParams) (sql.NullString, error)
 	GetWebhook(ctx context.Context, id string) (Webhook, error)
+	GetWebhookByDiscordChannel(ctx context.Context, channelID string) (Webhook, error)
+	GetWebhookCost(ctx context.Context, arg GetWebhookCostParams) (float64, error)
+	GetWebhookUsageStats(ctx context.Context, arg GetWebhookUsageStatsParams) (GetWebhookUsageStatsRow, error)
 	GetWebhookWithStats(ctx context.Context, id string) (GetWebhookWithStatsRow, error)
+	HasWebhookSession(ctx context.Context, arg HasWebhookSessionParams) (int64, error)
 	ListAPIKeysByWebhook(ctx context.Context, webhookID string) ([]ListAPIKeysByWebhookRow, error)
+	ListCallbackDeliveries(ctx context.Context, jobID int64) ([]CallbackDelivery, error)
+	ListDeadLetterJobs(ctx context.Context, arg ListDeadLetterJobsParams) ([]ListDeadLetterJobsRow, error)
+	ListDiscordChannels(ctx context.Context, webhookID string) ([]string, error)
 	ListExecutionHistoriesByWebhook(ctx context.Context, arg ListExecutionHistoriesByWebhookParams) ([]ExecutionHistory, error)
 	ListGlobalSettings(ctx context.Context) ([]GlobalSetting, error)
+	ListJobAttempts(ctx context.Context, jobID int64) ([]JobAttempt, error)
+	ListJobEventsAfter(ctx context.Context, arg ListJobEventsAfterParams) ([]JobEvent, error)
+	ListNotificationDeliveries(ctx context.Context, jobID int64) ([]NotificationDelivery, error)
 	ListWebhooks(ctx context.Context) ([]Webhook, error)
 	LogSecurityAuditEvent(ctx context.Context, arg LogSecurityAuditEventParams) error
+	RequeueDeadLetterJobs(ctx context.Context, arg RequeueDeadLetterJobsParams) (int64, error)
+	RequeueJob(ctx context.Context, id int64) (JobQueue, error)
+	ResendNotificationDelivery(ctx context.Context, id int64) (NotificationDelivery, error)
+	ResetSendingNotificationDeliveries(ctx context.Context) error
 	ResetStaleJobs(ctx context.Context) error
+	SetDiscordReplyMessage(ctx context.Context, arg SetDiscordReplyMessageParams) error
 	UpdateAPIKeyLastUsed(ctx context.Context, id int64) error
 	UpdateGlobalSetting(ctx context.Context, arg UpdateGlobalSettingParams) error
 	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) error
+	UpsertGitHubIntegration(ctx context.Context, arg UpsertGitHubIntegrationParams) error
 }
 
 var _ Querier = (*Queries)(nil)
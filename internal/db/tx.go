@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// InTx runs fn with queries bound to a new transaction, which is committed when fn
+// returns nil and rolled back otherwise. Queries already bound to a transaction run
+// fn in that transaction.
+func (q *Queries) InTx(ctx context.Context, fn func(*Queries) error) error {
+	conn, ok := q.db.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return fn(q)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(q.WithTx(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
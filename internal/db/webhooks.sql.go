@@ -19,31 +19,45 @@ INSERT INTO webhooks (
     allowed_tools, disallowed_tools,
     permission_mode, permission_prompt_tool_name,
     model, fallback_model, mcp_servers,
-    enable_continue, continue_minutes
+    enable_continue, continue_minutes,
+    max_concurrency,
+    retry_backoff_base_seconds, retry_backoff_factor,
+    retry_backoff_jitter, retry_backoff_max_seconds,
+    prompt_template, override_policy,
+    daily_budget_usd, monthly_budget_usd
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-RETURNING id, name, description, is_active, created_at, updated_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, notification_config, enable_continue, continue_minutes
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, description, is_active, created_at, updated_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, notification_config, enable_continue, continue_minutes, max_concurrency, retry_backoff_base_seconds, retry_backoff_factor, retry_backoff_jitter, retry_backoff_max_seconds, prompt_template, override_policy, daily_budget_usd, monthly_budget_usd
 `
 
 type CreateWebhookParams struct {
-	ID                       string         `json:"id"`
-	Name                     string         `json:"name"`
-	Description              sql.NullString `json:"description"`
-	NotificationConfig       interface{}    `json:"notification_config"`
-	WorkingDir               sql.NullString `json:"working_dir"`
-	MaxThinkingTokens        sql.NullInt64  `json:"max_thinking_tokens"`
-	MaxTurns                 sql.NullInt64  `json:"max_turns"`
-	CustomSystemPrompt       sql.NullString `json:"custom_system_prompt"`
-	AppendSystemPrompt       sql.NullString `json:"append_system_prompt"`
-	AllowedTools             sql.NullString `json:"allowed_tools"`
-	DisallowedTools          sql.NullString `json:"disallowed_tools"`
-	PermissionMode           sql.NullString `json:"permission_mode"`
-	PermissionPromptToolName sql.NullString `json:"permission_prompt_tool_name"`
-	Model                    sql.NullString `json:"model"`
-	FallbackModel            sql.NullString `json:"fallback_model"`
-	McpServers               sql.NullString `json:"mcp_servers"`
-	EnableContinue           bool           `json:"enable_continue"`
-	ContinueMinutes          int64          `json:"continue_minutes"`
+	ID                       string          `json:"id"`
+	Name                     string          `json:"name"`
+	Description              sql.NullString  `json:"description"`
+	NotificationConfig       interface{}     `json:"notification_config"`
+	WorkingDir               sql.NullString  `json:"working_dir"`
+	MaxThinkingTokens        sql.NullInt64   `json:"max_thinking_tokens"`
+	MaxTurns                 sql.NullInt64   `json:"max_turns"`
+	CustomSystemPrompt       sql.NullString  `json:"custom_system_prompt"`
+	AppendSystemPrompt       sql.NullString  `json:"append_system_prompt"`
+	AllowedTools             sql.NullString  `json:"allowed_tools"`
+	DisallowedTools          sql.NullString  `json:"disallowed_tools"`
+	PermissionMode           sql.NullString  `json:"permission_mode"`
+	PermissionPromptToolName sql.NullString  `json:"permission_prompt_tool_name"`
+	Model                    sql.NullString  `json:"model"`
+	FallbackModel            sql.NullString  `json:"fallback_model"`
+	McpServers               sql.NullString  `json:"mcp_servers"`
+	EnableContinue           bool            `json:"enable_continue"`
+	ContinueMinutes          int64           `json:"continue_minutes"`
+	MaxConcurrency           int64           `json:"max_concurrency"`
+	RetryBackoffBaseSeconds  int64           `json:"retry_backoff_base_seconds"`
+	RetryBackoffFactor       float64         `json:"retry_backoff_factor"`
+	RetryBackoffJitter       float64         `json:"retry_backoff_jitter"`
+	RetryBackoffMaxSeconds   int64           `json:"retry_backoff_max_seconds"`
+	PromptTemplate           sql.NullString  `json:"prompt_template"`
+	OverridePolicy           sql.NullString  `json:"override_policy"`
+	DailyBudgetUsd           sql.NullFloat64 `json:"daily_budget_usd"`
+	MonthlyBudgetUsd         sql.NullFloat64 `json:"monthly_budget_usd"`
 }
 
 func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
@@ -66,6 +80,15 @@ func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (W
 		arg.McpServers,
 		arg.EnableContinue,
 		arg.ContinueMinutes,
+		arg.MaxConcurrency,
+		arg.RetryBackoffBaseSeconds,
+		arg.RetryBackoffFactor,
+		arg.RetryBackoffJitter,
+		arg.RetryBackoffMaxSeconds,
+		arg.PromptTemplate,
+		arg.OverridePolicy,
+		arg.DailyBudgetUsd,
+		arg.MonthlyBudgetUsd,
 	)
 	var i Webhook
 	err := row.Scan(
@@ -90,6 +113,15 @@ func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (W
 		&i.NotificationConfig,
 		&i.EnableContinue,
 		&i.ContinueMinutes,
+		&i.MaxConcurrency,
+		&i.RetryBackoffBaseSeconds,
+		&i.RetryBackoffFactor,
+		&i.RetryBackoffJitter,
+		&i.RetryBackoffMaxSeconds,
+		&i.PromptTemplate,
+		&i.OverridePolicy,
+		&i.DailyBudgetUsd,
+		&i.MonthlyBudgetUsd,
 	)
 	return i, err
 }
@@ -104,7 +136,7 @@ func (q *Queries) DeleteWebhook(ctx context.Context, id string) error {
 }
 
 const getWebhook = `-- name: GetWebhook :one
-SELECT id, name, description, is_active, created_at, updated_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, notification_config, enable_continue, continue_minutes FROM webhooks WHERE id = ? AND is_active = 1
+SELECT id, name, description, is_active, created_at, updated_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, notification_config, enable_continue, continue_minutes, max_concurrency, retry_backoff_base_seconds, retry_backoff_factor, retry_backoff_jitter, retry_backoff_max_seconds, prompt_template, override_policy, daily_budget_usd, monthly_budget_usd FROM webhooks WHERE id = ? AND is_active = 1
 `
 
 func (q *Queries) GetWebhook(ctx context.Context, id string) (Webhook, error) {
@@ -132,13 +164,22 @@ func (q *Queries) GetWebhook(ctx context.Context, id string) (Webhook, error) {
 		&i.NotificationConfig,
 		&i.EnableContinue,
 		&i.ContinueMinutes,
+		&i.MaxConcurrency,
+		&i.RetryBackoffBaseSeconds,
+		&i.RetryBackoffFactor,
+		&i.RetryBackoffJitter,
+		&i.RetryBackoffMaxSeconds,
+		&i.PromptTemplate,
+		&i.OverridePolicy,
+		&i.DailyBudgetUsd,
+		&i.MonthlyBudgetUsd,
 	)
 	return i, err
 }
 
 const getWebhookWithStats = `-- name: GetWebhookWithStats :one
 SELECT 
-    w.id, w.name, w.description, w.is_active, w.created_at, w.updated_at, w.working_dir, w.max_thinking_tokens, w.max_turns, w.custom_system_prompt, w.append_system_prompt, w.allowed_tools, w.disallowed_tools, w.permission_mode, w.permission_prompt_tool_name, w.model, w.fallback_model, w.mcp_servers, w.notification_config, w.enable_continue, w.continue_minutes,
+    w.id, w.name, w.description, w.is_active, w.created_at, w.updated_at, w.working_dir, w.max_thinking_tokens, w.max_turns, w.custom_system_prompt, w.append_system_prompt, w.allowed_tools, w.disallowed_tools, w.permission_mode, w.permission_prompt_tool_name, w.model, w.fallback_model, w.mcp_servers, w.notification_config, w.enable_continue, w.continue_minutes, w.max_concurrency, w.retry_backoff_base_seconds, w.retry_backoff_factor, w.retry_backoff_jitter, w.retry_backoff_max_seconds, w.prompt_template, w.override_policy, w.daily_budget_usd, w.monthly_budget_usd,
     COUNT(DISTINCT ak.id) as api_key_count,
     COUNT(DISTINCT eh.id) as execution_count,
     MAX(eh.created_at) as last_execution
@@ -150,30 +191,39 @@ GROUP BY w.id
 `
 
 type GetWebhookWithStatsRow struct {
-	ID                       string         `json:"id"`
-	Name                     string         `json:"name"`
-	Description              sql.NullString `json:"description"`
-	IsActive                 bool           `json:"is_active"`
-	CreatedAt                time.Time      `json:"created_at"`
-	UpdatedAt                time.Time      `json:"updated_at"`
-	WorkingDir               sql.NullString `json:"working_dir"`
-	MaxThinkingTokens        sql.NullInt64  `json:"max_thinking_tokens"`
-	MaxTurns                 sql.NullInt64  `json:"max_turns"`
-	CustomSystemPrompt       sql.NullString `json:"custom_system_prompt"`
-	AppendSystemPrompt       sql.NullString `json:"append_system_prompt"`
-	AllowedTools             sql.NullString `json:"allowed_tools"`
-	DisallowedTools          sql.NullString `json:"disallowed_tools"`
-	PermissionMode           sql.NullString `json:"permission_mode"`
-	PermissionPromptToolName sql.NullString `json:"permission_prompt_tool_name"`
-	Model                    sql.NullString `json:"model"`
-	FallbackModel            sql.NullString `json:"fallback_model"`
-	McpServers               sql.NullString `json:"mcp_servers"`
-	NotificationConfig       interface{}    `json:"notification_config"`
-	EnableContinue           bool           `json:"enable_continue"`
-	ContinueMinutes          int64          `json:"continue_minutes"`
-	ApiKeyCount              int64          `json:"api_key_count"`
-	ExecutionCount           int64          `json:"execution_count"`
-	LastExecution            interface{}    `json:"last_execution"`
+	ID                       string          `json:"id"`
+	Name                     string          `json:"name"`
+	Description              sql.NullString  `json:"description"`
+	IsActive                 bool            `json:"is_active"`
+	CreatedAt                time.Time       `json:"created_at"`
+	UpdatedAt                time.Time       `json:"updated_at"`
+	WorkingDir               sql.NullString  `json:"working_dir"`
+	MaxThinkingTokens        sql.NullInt64   `json:"max_thinking_tokens"`
+	MaxTurns                 sql.NullInt64   `json:"max_turns"`
+	CustomSystemPrompt       sql.NullString  `json:"custom_system_prompt"`
+	AppendSystemPrompt       sql.NullString  `json:"append_system_prompt"`
+	AllowedTools             sql.NullString  `json:"allowed_tools"`
+	DisallowedTools          sql.NullString  `json:"disallowed_tools"`
+	PermissionMode           sql.NullString  `json:"permission_mode"`
+	PermissionPromptToolName sql.NullString  `json:"permission_prompt_tool_name"`
+	Model                    sql.NullString  `json:"model"`
+	FallbackModel            sql.NullString  `json:"fallback_model"`
+	McpServers               sql.NullString  `json:"mcp_servers"`
+	NotificationConfig       interface{}     `json:"notification_config"`
+	EnableContinue           bool            `json:"enable_continue"`
+	ContinueMinutes          int64           `json:"continue_minutes"`
+	MaxConcurrency           int64           `json:"max_concurrency"`
+	RetryBackoffBaseSeconds  int64           `json:"retry_backoff_base_seconds"`
+	RetryBackoffFactor       float64         `json:"retry_backoff_factor"`
+	RetryBackoffJitter       float64         `json:"retry_backoff_jitter"`
+	RetryBackoffMaxSeconds   int64           `json:"retry_backoff_max_seconds"`
+	PromptTemplate           sql.NullString  `json:"prompt_template"`
+	OverridePolicy           sql.NullString  `json:"override_policy"`
+	DailyBudgetUsd           sql.NullFloat64 `json:"daily_budget_usd"`
+	MonthlyBudgetUsd         sql.NullFloat64 `json:"monthly_budget_usd"`
+	ApiKeyCount              int64           `json:"api_key_count"`
+	ExecutionCount           int64           `json:"execution_count"`
+	LastExecution            interface{}     `json:"last_execution"`
 }
 
 func (q *Queries) GetWebhookWithStats(ctx context.Context, id string) (GetWebhookWithStatsRow, error) {
@@ -201,6 +251,15 @@ func (q *Queries) GetWebhookWithStats(ctx context.Context, id string) (GetWebhoo
 		&i.NotificationConfig,
 		&i.EnableContinue,
 		&i.ContinueMinutes,
+		&i.MaxConcurrency,
+		&i.RetryBackoffBaseSeconds,
+		&i.RetryBackoffFactor,
+		&i.RetryBackoffJitter,
+		&i.RetryBackoffMaxSeconds,
+		&i.PromptTemplate,
+		&i.OverridePolicy,
+		&i.DailyBudgetUsd,
+		&i.MonthlyBudgetUsd,
 		&i.ApiKeyCount,
 		&i.ExecutionCount,
 		&i.LastExecution,
@@ -209,7 +268,7 @@ func (q *Queries) GetWebhookWithStats(ctx context.Context, id string) (GetWebhoo
 }
 
 const listWebhooks = `-- name: ListWebhooks :many
-SELECT id, name, description, is_active, created_at, updated_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, notification_config, enable_continue, continue_minutes FROM webhooks WHERE is_active = 1 ORDER BY created_at DESC
+SELECT id, name, description, is_active, created_at, updated_at, working_dir, max_thinking_tokens, max_turns, custom_system_prompt, append_system_prompt, allowed_tools, disallowed_tools, permission_mode, permission_prompt_tool_name, model, fallback_model, mcp_servers, notification_config, enable_continue, continue_minutes, max_concurrency, retry_backoff_base_seconds, retry_backoff_factor, retry_backoff_jitter, retry_backoff_max_seconds, prompt_template, override_policy, daily_budget_usd, monthly_budget_usd FROM webhooks WHERE is_active = 1 ORDER BY created_at DESC
 `
 
 func (q *Queries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
@@ -243,6 +302,15 @@ func (q *Queries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
 			&i.NotificationConfig,
 			&i.EnableContinue,
 			&i.ContinueMinutes,
+			&i.MaxConcurrency,
+			&i.RetryBackoffBaseSeconds,
+			&i.RetryBackoffFactor,
+			&i.RetryBackoffJitter,
+			&i.RetryBackoffMaxSeconds,
+			&i.PromptTemplate,
+			&i.OverridePolicy,
+			&i.DailyBudgetUsd,
+			&i.MonthlyBudgetUsd,
 		); err != nil {
 			return nil, err
 		}
@@ -276,29 +344,47 @@ SET name = ?,
     mcp_servers = ?,
     enable_continue = ?,
     continue_minutes = ?,
+    max_concurrency = ?,
+    retry_backoff_base_seconds = ?,
+    retry_backoff_factor = ?,
+    retry_backoff_jitter = ?,
+    retry_backoff_max_seconds = ?,
+    prompt_template = ?,
+    override_policy = ?,
+    daily_budget_usd = ?,
+    monthly_budget_usd = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 `
 
 type UpdateWebhookParams struct {
-	Name                     string         `json:"name"`
-	Description              sql.NullString `json:"description"`
-	NotificationConfig       interface{}    `json:"notification_config"`
-	WorkingDir               sql.NullString `json:"working_dir"`
-	MaxThinkingTokens        sql.NullInt64  `json:"max_thinking_tokens"`
-	MaxTurns                 sql.NullInt64  `json:"max_turns"`
-	CustomSystemPrompt       sql.NullString `json:"custom_system_prompt"`
-	AppendSystemPrompt       sql.NullString `json:"append_system_prompt"`
-	AllowedTools             sql.NullString `json:"allowed_tools"`
-	DisallowedTools          sql.NullString `json:"disallowed_tools"`
-	PermissionMode           sql.NullString `json:"permission_mode"`
-	PermissionPromptToolName sql.NullString `json:"permission_prompt_tool_name"`
-	Model                    sql.NullString `json:"model"`
-	FallbackModel            sql.NullString `json:"fallback_model"`
-	McpServers               sql.NullString `json:"mcp_servers"`
-	EnableContinue           bool           `json:"enable_continue"`
-	ContinueMinutes          int64          `json:"continue_minutes"`
-	ID                       string         `json:"id"`
+	Name                     string          `json:"name"`
+	Description              sql.NullString  `json:"description"`
+	NotificationConfig       interface{}     `json:"notification_config"`
+	WorkingDir               sql.NullString  `json:"working_dir"`
+	MaxThinkingTokens        sql.NullInt64   `json:"max_thinking_tokens"`
+	MaxTurns                 sql.NullInt64   `json:"max_turns"`
+	CustomSystemPrompt       sql.NullString  `json:"custom_system_prompt"`
+	AppendSystemPrompt       sql.NullString  `json:"append_system_prompt"`
+	AllowedTools             sql.NullString  `json:"allowed_tools"`
+	DisallowedTools          sql.NullString  `json:"disallowed_tools"`
+	PermissionMode           sql.NullString  `json:"permission_mode"`
+	PermissionPromptToolName sql.NullString  `json:"permission_prompt_tool_name"`
+	Model                    sql.NullString  `json:"model"`
+	FallbackModel            sql.NullString  `json:"fallback_model"`
+	McpServers               sql.NullString  `json:"mcp_servers"`
+	EnableContinue           bool            `json:"enable_continue"`
+	ContinueMinutes          int64           `json:"continue_minutes"`
+	MaxConcurrency           int64           `json:"max_concurrency"`
+	RetryBackoffBaseSeconds  int64           `json:"retry_backoff_base_seconds"`
+	RetryBackoffFactor       float64         `json:"retry_backoff_factor"`
+	RetryBackoffJitter       float64         `json:"retry_backoff_jitter"`
+	RetryBackoffMaxSeconds   int64           `json:"retry_backoff_max_seconds"`
+	PromptTemplate           sql.NullString  `json:"prompt_template"`
+	OverridePolicy           sql.NullString  `json:"override_policy"`
+	DailyBudgetUsd           sql.NullFloat64 `json:"daily_budget_usd"`
+	MonthlyBudgetUsd         sql.NullFloat64 `json:"monthly_budget_usd"`
+	ID                       string          `json:"id"`
 }
 
 func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) error {
@@ -320,6 +406,15 @@ func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) er
 		arg.McpServers,
 		arg.EnableContinue,
 		arg.ContinueMinutes,
+		arg.MaxConcurrency,
+		arg.RetryBackoffBaseSeconds,
+		arg.RetryBackoffFactor,
+		arg.RetryBackoffJitter,
+		arg.RetryBackoffMaxSeconds,
+		arg.PromptTemplate,
+		arg.OverridePolicy,
+		arg.DailyBudgetUsd,
+		arg.MonthlyBudgetUsd,
 		arg.ID,
 	)
 	return err
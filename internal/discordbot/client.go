@@ -0,0 +1,144 @@
+package discordbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// DefaultAPIBaseURL is the Discord REST API the client talks to unless configured otherwise
+	DefaultAPIBaseURL = "https://discord.com/api/v10"
+	// Timeout bounds a single API request
+	Timeout = 30 * time.Second
+	// errorCodeThreadExists is returned when a thread was already started from a message
+	errorCodeThreadExists = 160004
+)
+
+// Client calls the Discord REST API. Interaction webhooks work without a bot
+// token; creating threads and messages requires one.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: Timeout},
+	}
+}
+
+// HasToken reports whether the client can make bot requests
+func (c *Client) HasToken() bool {
+	return c.token != ""
+}
+
+// GetOriginalResponse returns the message created by an interaction response.
+// The interaction token is valid for 15 minutes.
+func (c *Client) GetOriginalResponse(applicationID, interactionToken string) (Message, error) {
+	var message Message
+	err := c.do(http.MethodGet, fmt.Sprintf("/webhooks/%s/%s/messages/@original", applicationID, interactionToken), false, nil, &message)
+	return message, err
+}
+
+// StartThread starts a thread from a message. A thread started from a message
+// has the same ID as the message, so starting it twice is not an error.
+func (c *Client) StartThread(channelID, messageID, name string) error {
+	err := c.do(http.MethodPost, fmt.Sprintf("/channels/%s/messages/%s/threads", channelID, messageID), true, map[string]interface{}{
+		"name": name,
+	}, nil)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == errorCodeThreadExists {
+		return nil
+	}
+	return err
+}
+
+// CreateMessage posts a message to a channel or thread without pinging anyone
+func (c *Client) CreateMessage(channelID, content string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/channels/%s/messages", channelID), true, ResponseData{
+		Content:         content,
+		AllowedMentions: &AllowedMentions{Parse: []string{}},
+	}, nil)
+}
+
+// RegisterCommand creates the global application command, replacing an
+// existing command of the same name
+func (c *Client) RegisterCommand(applicationID string, command Command) error {
+	return c.do(http.MethodPost, fmt.Sprintf("/applications/%s/commands", applicationID), true, command, nil)
+}
+
+// do sends a JSON request and decodes the response into out if it is not nil.
+// Rate limits are returned as a notifier.RetryAfterError.
+func (c *Client) do(method, path string, auth bool, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal Discord request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if auth {
+		if c.token == "" {
+			return errors.New("Discord bot token is not configured")
+		}
+		req.Header.Set("Authorization", "Bot "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Discord request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var rateLimit struct {
+			RetryAfter float64 `json:"retry_after"`
+		}
+		retryAfter := notifier.RetryAfterHeader(resp.Header)
+		if err := json.Unmarshal(respBody, &rateLimit); err == nil && rateLimit.RetryAfter > 0 {
+			retryAfter = time.Duration(rateLimit.RetryAfter * float64(time.Second))
+		}
+		return &notifier.RetryAfterError{Err: errors.New("Discord API rate limited the request"), RetryAfter: retryAfter}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{Status: resp.StatusCode}
+		if err := json.Unmarshal(respBody, apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(respBody))
+		}
+		return notifier.StatusError(resp.StatusCode, apiErr)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode Discord response: %w", err)
+		}
+	}
+	return nil
+}
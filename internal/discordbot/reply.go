@@ -0,0 +1,92 @@
+package discordbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// NotifierName is the notifier of the outbox deliveries that answer a command
+	NotifierName = "discord_thread"
+	// MaxMessageLen is the limit Discord puts on the content of a message
+	MaxMessageLen = 2000
+	// MaxReplyMessages bounds how many messages one reply is split into
+	MaxReplyMessages = 5
+	// MaxThreadNameLen is the limit Discord puts on thread names
+	MaxThreadNameLen = 100
+)
+
+// ReplyConfig is the notification config of a reply to a command. Without a
+// message ID the reply is posted to the channel instead of a thread.
+type ReplyConfig struct {
+	ChannelID string `json:"channel_id"`
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// ReplyNotifier posts a job result as a reply to the command that submitted it
+type ReplyNotifier struct {
+	client *Client
+	config ReplyConfig
+}
+
+func NewReplyNotifier(client *Client, config ReplyConfig) *ReplyNotifier {
+	return &ReplyNotifier{
+		client: client,
+		config: config,
+	}
+}
+
+func (n *ReplyNotifier) SendNotification(resp *models.WebhookResponse) error {
+	channelID := n.config.ChannelID
+	if n.config.MessageID != "" {
+		// Thread names are a single line
+		name := notifier.Truncate(fmt.Sprintf("Job #%d: %s", resp.JobID, strings.Join(strings.Fields(resp.Prompt), " ")), MaxThreadNameLen, "")
+		if err := n.client.StartThread(n.config.ChannelID, n.config.MessageID, name); err != nil {
+			return err
+		}
+		// A thread started from a message has the message's ID
+		channelID = n.config.MessageID
+	}
+
+	for _, content := range replyMessages(resp) {
+		if err := n.client.CreateMessage(channelID, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ReplyNotifier) Name() string {
+	return NotifierName
+}
+
+// replyMessages renders the result and splits it into messages Discord accepts
+func replyMessages(resp *models.WebhookResponse) []string {
+	var text string
+	switch {
+	case resp.Event == models.EventCancelled:
+		text = fmt.Sprintf(":stop_sign: Job #%d was cancelled.", resp.JobID)
+	case !resp.Success:
+		text = fmt.Sprintf(":x: Job #%d failed after %s:\n%s", resp.JobID, resp.ExecutionTime, resp.Error)
+	case resp.Response == "":
+		text = fmt.Sprintf(":white_check_mark: Job #%d finished in %s without a response.", resp.JobID, resp.ExecutionTime)
+	default:
+		text = resp.Response
+	}
+
+	var messages []string
+	for text != "" && len(messages) < MaxReplyMessages {
+		chunk := notifier.Truncate(text, MaxMessageLen, "")
+		if len(messages) == MaxReplyMessages-1 && len(chunk) < len(text) {
+			chunk = notifier.Truncate(text, MaxMessageLen-40, "") + "\n\n(response too long, truncated)"
+			text = ""
+		} else {
+			text = text[len(chunk):]
+		}
+		messages = append(messages, chunk)
+	}
+	return messages
+}
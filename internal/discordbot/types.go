@@ -0,0 +1,122 @@
+package discordbot
+
+import "fmt"
+
+// Interaction types sent to the interactions endpoint
+const (
+	InteractionTypePing               = 1
+	InteractionTypeApplicationCommand = 2
+)
+
+// Interaction response types
+const (
+	ResponseTypePong                     = 1
+	ResponseTypeChannelMessageWithSource = 4
+)
+
+// MessageFlagEphemeral shows a response only to the user who ran the command
+const MessageFlagEphemeral = 1 << 6
+
+// Application command option types
+const (
+	OptionTypeString = 3
+)
+
+// Interaction is the part of an incoming interaction the bot uses
+type Interaction struct {
+	ID            string       `json:"id"`
+	ApplicationID string       `json:"application_id"`
+	Type          int          `json:"type"`
+	Data          *CommandData `json:"data,omitempty"`
+	ChannelID     string       `json:"channel_id"`
+	Token         string       `json:"token"`
+	// Member is set in guild channels and User in direct messages
+	Member *Member `json:"member,omitempty"`
+	User   *User   `json:"user,omitempty"`
+}
+
+type CommandData struct {
+	Name    string          `json:"name"`
+	Options []CommandOption `json:"options,omitempty"`
+}
+
+type CommandOption struct {
+	Name  string      `json:"name"`
+	Type  int         `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type Member struct {
+	User *User `json:"user,omitempty"`
+}
+
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// StringOption returns the value of a string option, or "" if it was not given
+func (d *CommandData) StringOption(name string) string {
+	for _, option := range d.Options {
+		if option.Name == name {
+			if s, ok := option.Value.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Author returns the user who ran the command
+func (i *Interaction) Author() *User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+type InteractionResponse struct {
+	Type int           `json:"type"`
+	Data *ResponseData `json:"data,omitempty"`
+}
+
+type ResponseData struct {
+	Content         string           `json:"content"`
+	Flags           int              `json:"flags,omitempty"`
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+// AllowedMentions with an empty Parse list keeps a message from pinging anyone
+type AllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+type Message struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// Command is an application command as registered with Discord
+type Command struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Options     []CommandOptionDef `json:"options,omitempty"`
+}
+
+type CommandOptionDef struct {
+	Type        int    `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// APIError is an error response of the Discord REST API
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Discord API returned status %d: %s (code %d)", e.Status, e.Message, e.Code)
+}
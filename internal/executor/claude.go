@@ -3,12 +3,17 @@ package executor
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"os"
 	"strings"
 	"time"
 
 	claude "github.com/upamune/claude-code-go"
 	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/types"
 )
 
 type ClaudeExecutor struct {
@@ -23,8 +28,41 @@ func NewClaudeExecutor(timeout time.Duration, queries *db.Queries) *ClaudeExecut
 	}
 }
 
-// ExecuteWithOptions executes Claude with specific options from job
-func (e *ClaudeExecutor) ExecuteWithOptions(ctx context.Context, prompt string, job db.JobQueue) (string, error) {
+// Result is the outcome of an execution
+type Result struct {
+	Response string
+	// SessionID identifies the Claude conversation so later jobs can resume it
+	SessionID string
+	Usage     Usage
+}
+
+// Usage is what an execution consumed, as reported by Claude
+type Usage struct {
+	InputTokens              int64
+	OutputTokens             int64
+	CacheReadInputTokens     int64
+	CacheCreationInputTokens int64
+	TotalCostUSD             float64
+	NumTurns                 int64
+	DurationMs               int64
+}
+
+func newUsage(result *claude.ResultMessage) Usage {
+	return Usage{
+		InputTokens:              int64(result.Usage.InputTokens),
+		OutputTokens:             int64(result.Usage.OutputTokens),
+		CacheReadInputTokens:     int64(result.Usage.CacheReadInputTokens),
+		CacheCreationInputTokens: int64(result.Usage.CacheCreationInputTokens),
+		TotalCostUSD:             result.TotalCostUSD,
+		NumTurns:                 int64(result.NumTurns),
+		DurationMs:               result.DurationMS,
+	}
+}
+
+// ExecuteWithOptions executes Claude with specific options from job. When
+// Claude reports an error result, the returned Result still carries the
+// session and the usage of the failed execution.
+func (e *ClaudeExecutor) ExecuteWithOptions(ctx context.Context, prompt string, job db.JobQueue) (Result, error) {
 	opts := &claude.Options{
 		WorkingDir:          job.WorkingDir.String,
 		MaxThinkingTokens:   intPtrFromNullInt64(job.MaxThinkingTokens),
@@ -64,23 +102,47 @@ func (e *ClaudeExecutor) ExecuteWithOptions(ctx context.Context, prompt string,
 		opts.PermissionPromptToolName = job.PermissionPromptToolName.String
 	}
 
-	// TODO: Parse MCP servers from job.McpServers if needed
+	// Parse MCP servers configured on the webhook
+	if job.McpServers.Valid && job.McpServers.String != "" {
+		servers, err := mcpServers(job.McpServers.String)
+		if err != nil {
+			return Result{}, Permanent(err)
+		}
+		opts.MCPServers = servers
+	}
 
-	// Handle --continue flag based on last execution time
-	if job.EnableContinue && e.queries != nil {
+	// Resume the requested session, or the latest one of the job's thread
+	resume, err := e.resumeSessionID(ctx, job)
+	if err != nil {
+		return Result{}, err
+	}
+	opts.Resume = resume
+
+	// Handle --continue flag based on last execution time. Jobs that name a
+	// session or thread never fall back to whatever ran last in the webhook.
+	if job.EnableContinue && !job.ResumeSessionID.Valid && !job.Thread.Valid && e.queries != nil {
 		lastExecution, err := e.queries.GetLastExecution(ctx, job.WebhookID)
 		if err == nil {
 			// Check if last execution was within the specified time window
 			minutesSinceLastExecution := time.Since(lastExecution.CreatedAt).Minutes()
 			if minutesSinceLastExecution <= float64(job.ContinueMinutes) {
 				opts.Continue = true
-				fmt.Printf("Enabling --continue flag (last execution was %.1f minutes ago)\n", minutesSinceLastExecution)
+				slog.InfoContext(ctx, "Enabling --continue flag", "minutes_since_last_execution", minutesSinceLastExecution)
 			} else {
-				fmt.Printf("Not enabling --continue flag (last execution was %.1f minutes ago, threshold is %d minutes)\n", minutesSinceLastExecution, job.ContinueMinutes)
+				slog.InfoContext(ctx, "Not enabling --continue flag", "minutes_since_last_execution", minutesSinceLastExecution, "continue_minutes", job.ContinueMinutes)
 			}
 		} else if err != sql.ErrNoRows {
 			// Log error but continue execution without --continue flag
-			fmt.Printf("Error checking last execution: %v\n", err)
+			slog.ErrorContext(ctx, "Failed to check last execution", "error", err)
+		}
+	}
+
+	// A missing working directory fails the same way on every attempt
+	if opts.WorkingDir != "" {
+		if info, err := os.Stat(opts.WorkingDir); err != nil {
+			return Result{}, Permanent(fmt.Errorf("working directory %s: %w", opts.WorkingDir, err))
+		} else if !info.IsDir() {
+			return Result{}, Permanent(fmt.Errorf("working directory %s is not a directory", opts.WorkingDir))
 		}
 	}
 
@@ -89,21 +151,115 @@ func (e *ClaudeExecutor) ExecuteWithOptions(ctx context.Context, prompt string,
 	defer cancel()
 
 	// Log execution details for debugging
-	fmt.Printf("Executing claude with options: WorkingDir=%s, Model=%s, Prompt=%s\n", 
-		opts.WorkingDir, opts.Model, prompt)
+	slog.InfoContext(ctx, "Executing claude",
+		"working_dir", opts.WorkingDir, "model", opts.Model, "resume", opts.Resume, logging.Prompt(prompt))
 	
-	// Execute
-	result, err := claude.Query(ctx, prompt, opts)
+	// Execute with streaming so progress is visible while the job runs
+	stream, err := claude.QueryStream(ctx, prompt, opts)
 	if err != nil {
+		slog.ErrorContext(ctx, "Claude execution error", "error", fmt.Sprintf("%+v", err))
+		return Result{}, fmt.Errorf("execution error: %w", err)
+	}
+	defer stream.Close()
+
+	var result *claude.ResultMessage
+	for msg := range stream.Messages {
+		if msg.Err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return Result{}, fmt.Errorf("execution timeout after %v", e.timeout)
+			}
+			// Log the full error details
+			slog.ErrorContext(ctx, "Claude execution error", "error", fmt.Sprintf("%+v", msg.Err))
+			return Result{}, fmt.Errorf("execution error: %w", msg.Err)
+		}
+
+		e.recordEvent(ctx, job.ID, msg.Message)
+
+		if r, ok := msg.Message.(*claude.ResultMessage); ok {
+			result = r
+		}
+	}
+
+	if result == nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("execution timeout after %v", e.timeout)
+			return Result{}, fmt.Errorf("execution timeout after %v", e.timeout)
 		}
-		// Log the full error details
-		fmt.Printf("Claude execution error: %+v\n", err)
-		return "", fmt.Errorf("execution error: %v", err)
+		return Result{}, fmt.Errorf("execution error: claude exited without a result")
+	}
+	execution := Result{SessionID: result.SessionID, Usage: newUsage(result)}
+	if result.IsError {
+		err := fmt.Errorf("execution error: %s", result.Subtype)
+		// Hitting the turn limit again is the expected outcome of a retry
+		if result.Subtype == "error_max_turns" {
+			return execution, Permanent(err)
+		}
+		return execution, err
 	}
 
-	return result.Result, nil
+	execution.Response = result.Result
+	return execution, nil
+}
+
+// resumeSessionID returns the session a job continues: the one it asked for,
+// or the one its thread last ended in. A thread without a completed job yet
+// starts a new session.
+func (e *ClaudeExecutor) resumeSessionID(ctx context.Context, job db.JobQueue) (string, error) {
+	if job.ResumeSessionID.Valid {
+		return job.ResumeSessionID.String, nil
+	}
+	if !job.Thread.Valid || e.queries == nil {
+		return "", nil
+	}
+
+	sessionID, err := e.queries.GetThreadSessionID(ctx, db.GetThreadSessionIDParams{
+		WebhookID: job.WebhookID,
+		Thread:    job.Thread,
+	})
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up thread %s: %w", job.Thread.String, err)
+	}
+	return sessionID.String, nil
+}
+
+// recordEvent persists a streamed message so it can be tailed while the job runs
+func (e *ClaudeExecutor) recordEvent(ctx context.Context, jobID int64, msg claude.Message) {
+	if e.queries == nil || jobID == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal job event", "error", err)
+		return
+	}
+
+	if err := e.queries.CreateJobEvent(ctx, db.CreateJobEventParams{
+		JobID:     jobID,
+		EventType: eventType(msg),
+		Payload:   string(payload),
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to record job event", "error", err)
+	}
+}
+
+func eventType(msg claude.Message) string {
+	switch msg.(type) {
+	case *claude.SystemMessage:
+		return "system"
+	case *claude.AssistantMessage:
+		return "assistant"
+	case *claude.UserMessage:
+		return "user"
+	case *claude.ResultMessage:
+		return "result"
+	case *claude.PermissionRequestMessage:
+		return "permission_request"
+	default:
+		return "unknown"
+	}
 }
 
 // Execute is a simple wrapper for backward compatibility
@@ -124,6 +280,27 @@ func (e *ClaudeExecutor) Execute(prompt string) (string, error) {
 	return result.Result, nil
 }
 
+// mcpServers converts the stored mcp_servers JSON into Claude SDK server configs
+func mcpServers(raw string) (map[string]claude.MCPServerConfig, error) {
+	parsed, err := types.ParseMCPServers(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mcp_servers: %w", err)
+	}
+
+	servers := make(map[string]claude.MCPServerConfig, len(parsed))
+	for name, server := range parsed {
+		switch s := server.(type) {
+		case types.MCPStdioServerConfig:
+			servers[name] = claude.MCPStdioServerConfig{Command: s.Command, Args: s.Args, Env: s.Env}
+		case types.MCPSSEServerConfig:
+			servers[name] = claude.MCPSSEServerConfig{URL: s.URL, Headers: s.Headers}
+		case types.MCPHTTPServerConfig:
+			servers[name] = claude.MCPHTTPServerConfig{URL: s.URL, Headers: s.Headers}
+		}
+	}
+	return servers, nil
+}
+
 func intPtrFromNullInt64(n sql.NullInt64) *int {
 	if !n.Valid {
 		return nil
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+
+	claude "github.com/upamune/claude-code-go"
+)
+
+// PermanentError marks an execution failure that would fail the same way if
+// retried, such as invalid options or a missing working directory
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so that IsRetryable reports false for it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsRetryable reports whether a failed execution is worth retrying.
+// Timeouts, API overloads and other transient CLI failures are retryable;
+// configuration problems are not.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	// Invalid options are rejected by the SDK before the CLI is started
+	var configErr *claude.ConfigError
+	if errors.As(err, &configErr) {
+		return false
+	}
+
+	// The CLI or the working directory does not exist
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,39 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"testing"
+
+	claude "github.com/upamune/claude-code-go"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"permanent", Permanent(errors.New("invalid MCP servers")), false},
+		{"wrapped permanent", fmt.Errorf("Claude execution failed: %w", Permanent(errors.New("bad"))), false},
+		{"config error", &claude.ConfigError{Field: "model", Reason: "empty"}, false},
+		{"wrapped config error", fmt.Errorf("execution error: %w", &claude.ConfigError{Field: "max_turns"}), false},
+		{"missing working directory", fmt.Errorf("working directory: %w", fs.ErrNotExist), false},
+		{"missing CLI", &exec.Error{Name: "claude", Err: exec.ErrNotFound}, false},
+		{"timeout", fmt.Errorf("execution timeout after %v", "1h"), true},
+		{"deadline exceeded", fmt.Errorf("execution error: %w", context.DeadlineExceeded), true},
+		{"transient failure", errors.New("execution error: overloaded"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package githubbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// DefaultAPIBaseURL is the GitHub REST API the client talks to unless configured otherwise
+	DefaultAPIBaseURL = "https://api.github.com"
+	// Timeout bounds a single request
+	Timeout = 30 * time.Second
+)
+
+// Commenter posts comments on issues and pull requests. The worker only depends
+// on this interface so the GitHub API can be replaced, e.g. by a stub in tests.
+type Commenter interface {
+	CreateComment(ctx context.Context, repository string, number int64, body string) error
+}
+
+// Client is the Commenter that uses the GitHub REST API with a personal access
+// token or an installation token allowed to write issues and pull requests
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: Timeout},
+	}
+}
+
+// HasToken reports whether the client can post comments
+func (c *Client) HasToken() bool {
+	return c.token != ""
+}
+
+// CreateComment comments on an issue or pull request of a repository given as "owner/name"
+func (c *Client) CreateComment(ctx context.Context, repository string, number int64, body string) error {
+	if c.token == "" {
+		return errors.New("GitHub token is not configured")
+	}
+
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.baseURL, repository, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claude-code-pull-worker")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if retryAfter, limited := rateLimit(resp); limited {
+		return &notifier.RetryAfterError{
+			Err:        errors.New("GitHub rate limited the request"),
+			RetryAfter: retryAfter,
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return notifier.StatusError(resp.StatusCode, fmt.Errorf("GitHub returned unexpected status: %d %s", resp.StatusCode, strings.TrimSpace(string(respBody))))
+	}
+	return nil
+}
+
+// rateLimit reports whether a response is a primary or secondary rate limit and
+// how long to wait. GitHub answers both with 403 or 429.
+func rateLimit(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if retryAfter := notifier.RetryAfterHeader(resp.Header); retryAfter > 0 {
+		return retryAfter, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, true
+		}
+		return max(time.Until(time.Unix(reset, 0)), 0), true
+	}
+	return 0, resp.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,130 @@
+package githubbot
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DefaultPromptTemplate is used when an integration does not set its own
+const DefaultPromptTemplate = `{{if .IsPullRequest}}Pull request{{else}}Issue{{end}} #{{.Number}} in {{.Repository}}: {{.Title}}
+{{.URL}}
+
+{{.Body}}
+{{- if .Comment}}
+
+Comment by @{{.Author}}{{if .Path}} on {{.Path}}{{end}}:
+{{.Comment}}
+{{- end}}`
+
+// PromptData is what prompt templates are rendered with
+type PromptData struct {
+	Event      string
+	Action     string
+	Repository string
+	Number     int64
+	Title      string
+	Body       string
+	// URL links to the comment, or to the issue or pull request
+	URL           string
+	IsPullRequest bool
+	// Author is who wrote the comment, or opened the issue or pull request
+	Author string
+	// AuthorAssociation is the relation of Author to the repository, such as OWNER or NONE
+	AuthorAssociation string
+	// Comment is empty for issues and pull_request events
+	Comment string
+	// Path is the file a pull request review comment is on
+	Path string
+}
+
+// NewPromptData extracts the prompt data of a delivery. It reports false for
+// events and actions that do not trigger a job: new comments, and opened issues
+// and pull requests.
+func NewPromptData(event string, payload *Payload) (PromptData, bool) {
+	data := PromptData{Event: event, Action: payload.Action}
+	if payload.Repository != nil {
+		data.Repository = payload.Repository.FullName
+	}
+
+	var issue *Issue
+	switch {
+	case event == EventIssueComment && payload.Action == "created" && payload.Comment != nil:
+		issue = payload.Issue
+		data.IsPullRequest = issue != nil && issue.PullRequest != nil
+	case event == EventPullRequestReviewComment && payload.Action == "created" && payload.Comment != nil:
+		issue = payload.PullRequest
+		data.IsPullRequest = true
+	case event == EventIssues && payload.Action == "opened":
+		issue = payload.Issue
+	case event == EventPullRequest && payload.Action == "opened":
+		issue = payload.PullRequest
+		data.IsPullRequest = true
+	}
+	if issue == nil {
+		return PromptData{}, false
+	}
+
+	data.Number = issue.Number
+	data.Title = issue.Title
+	data.Body = issue.Body
+	data.URL = issue.HTMLURL
+	data.AuthorAssociation = issue.AuthorAssociation
+	if issue.User != nil {
+		data.Author = issue.User.Login
+	}
+	if payload.Comment != nil {
+		data.Comment = payload.Comment.Body
+		data.Path = payload.Comment.Path
+		data.URL = payload.Comment.HTMLURL
+		data.AuthorAssociation = payload.Comment.AuthorAssociation
+		if payload.Comment.User != nil {
+			data.Author = payload.Comment.User.Login
+		}
+	}
+	return data, true
+}
+
+// TriggerText is the text searched for the trigger phrase: the comment, or the
+// description of a newly opened issue or pull request
+func (d PromptData) TriggerText() string {
+	if d.Comment != "" {
+		return d.Comment
+	}
+	return d.Body
+}
+
+// AuthorAllowed reports whether the author's association is in the comma-separated
+// allow-list of an integration
+func (d PromptData) AuthorAllowed(allowed string) bool {
+	return d.AuthorAssociation != "" && slices.Contains(strings.Split(allowed, ","), d.AuthorAssociation)
+}
+
+// Triggers reports whether the text contains the trigger phrase, ignoring case.
+// An empty phrase triggers on every delivery.
+func Triggers(text, phrase string) bool {
+	return strings.Contains(strings.ToLower(text), strings.ToLower(phrase))
+}
+
+// ParsePromptTemplate parses a prompt template, falling back to DefaultPromptTemplate when s is empty
+func ParsePromptTemplate(s string) (*template.Template, error) {
+	if strings.TrimSpace(s) == "" {
+		s = DefaultPromptTemplate
+	}
+	return template.New("prompt").Option("missingkey=error").Parse(s)
+}
+
+// RenderPrompt renders the prompt of a delivery
+func RenderPrompt(tmpl string, data PromptData) (string, error) {
+	t, err := ParsePromptTemplate(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
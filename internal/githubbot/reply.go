@@ -0,0 +1,66 @@
+package githubbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// NotifierName is the notifier of the outbox deliveries that comment job results
+	NotifierName = "github_comment"
+	// MaxCommentLen is the longest comment GitHub accepts
+	MaxCommentLen = 65536
+	// CommentMarker is hidden in result comments so they never trigger another job,
+	// even when the token belongs to a regular user
+	CommentMarker = "<!-- claude-code-pull-worker -->"
+)
+
+// ReplyConfig is the notification config of a result comment
+type ReplyConfig struct {
+	Repository  string `json:"repository"`
+	IssueNumber int64  `json:"issue_number"`
+}
+
+// ReplyNotifier comments the result of a job on the issue or pull request that triggered it
+type ReplyNotifier struct {
+	commenter Commenter
+	config    ReplyConfig
+}
+
+func NewReplyNotifier(commenter Commenter, config ReplyConfig) *ReplyNotifier {
+	return &ReplyNotifier{
+		commenter: commenter,
+		config:    config,
+	}
+}
+
+func (n *ReplyNotifier) SendNotification(resp *models.WebhookResponse) error {
+	return n.commenter.CreateComment(context.Background(), n.config.Repository, n.config.IssueNumber, CommentBody(resp))
+}
+
+func (n *ReplyNotifier) Name() string {
+	return NotifierName
+}
+
+// CommentBody renders the result of a job as a Markdown comment
+func CommentBody(resp *models.WebhookResponse) string {
+	var body string
+	switch {
+	case resp.Event == models.EventCancelled:
+		body = fmt.Sprintf(":no_entry_sign: Job #%d was cancelled.", resp.JobID)
+	case !resp.Success:
+		body = fmt.Sprintf(":x: **Job #%d failed** after %s\n\n```\n%s\n```", resp.JobID, resp.ExecutionTime, strings.ReplaceAll(resp.Error, "```", "` ` `"))
+	default:
+		body = fmt.Sprintf(":white_check_mark: **Job #%d finished** in %s\n\n%s", resp.JobID, resp.ExecutionTime, resp.Response)
+	}
+
+	if utf8.RuneCountInString(body) > MaxCommentLen-100 {
+		body = notifier.Truncate(body, MaxCommentLen-100, "") + "\n\n*(response too long, truncated)*"
+	}
+	return CommentMarker + "\n" + body
+}
@@ -0,0 +1,70 @@
+package githubbot
+
+// Events that can trigger a job
+const (
+	EventIssues                   = "issues"
+	EventIssueComment             = "issue_comment"
+	EventPullRequest              = "pull_request"
+	EventPullRequestReviewComment = "pull_request_review_comment"
+	// EventPing is sent when the webhook is created
+	EventPing = "ping"
+)
+
+// Events lists the events a GitHub integration can subscribe to
+var Events = []string{EventIssueComment, EventPullRequestReviewComment, EventIssues, EventPullRequest}
+
+// AuthorAssociations lists the relations to a repository GitHub reports for the
+// author of a comment, issue or pull request
+var AuthorAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR", "CONTRIBUTOR", "FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER", "MANNEQUIN", "NONE"}
+
+// DefaultAllowedAssociations are the authors who can trigger a job unless an
+// integration allows others: people with write access to the repository
+const DefaultAllowedAssociations = "OWNER,MEMBER,COLLABORATOR"
+
+// Payload is the part of a webhook delivery the adapter uses
+type Payload struct {
+	Action      string      `json:"action"`
+	Issue       *Issue      `json:"issue,omitempty"`
+	PullRequest *Issue      `json:"pull_request,omitempty"`
+	Comment     *Comment    `json:"comment,omitempty"`
+	Repository  *Repository `json:"repository,omitempty"`
+	Sender      *User       `json:"sender,omitempty"`
+}
+
+// Issue is an issue or a pull request, which GitHub treats as an issue with extra fields
+type Issue struct {
+	Number  int64  `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    *User  `json:"user,omitempty"`
+	// AuthorAssociation is the relation of User to the repository, such as OWNER or NONE
+	AuthorAssociation string `json:"author_association"`
+	// PullRequest is set on the issue of an issue_comment delivery when the comment is on a pull request
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+type Comment struct {
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    *User  `json:"user,omitempty"`
+	// AuthorAssociation is the relation of User to the repository, such as OWNER or NONE
+	AuthorAssociation string `json:"author_association"`
+	// Path is the file a pull request review comment is on
+	Path string `json:"path,omitempty"`
+}
+
+type Repository struct {
+	FullName string `json:"full_name"`
+}
+
+type User struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// IsBot reports whether the user is a GitHub App or bot account, such as the
+// one that posts the results
+func (u *User) IsBot() bool {
+	return u != nil && u.Type == "Bot"
+}
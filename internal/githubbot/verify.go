@@ -0,0 +1,19 @@
+package githubbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Verify checks the X-Hub-Signature-256 header, "sha256=" followed by the
+// HMAC-SHA256 of the body keyed with the webhook secret
+func Verify(secret, signature string, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
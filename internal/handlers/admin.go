@@ -1,24 +1,33 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"html/template"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/upamune/claude-code-pull-worker/internal/db"
 	"github.com/upamune/claude-code-pull-worker/internal/templates"
 )
 
+// JobCanceller cancels pending or running jobs
+type JobCanceller interface {
+	CancelJob(ctx context.Context, jobID int64) (db.JobQueue, error)
+}
+
 type AdminHandler struct {
-	queries *db.Queries
+	queries   *db.Queries
+	canceller JobCanceller
 }
 
-func NewAdminHandler(queries *db.Queries) (*AdminHandler, error) {
+func NewAdminHandler(queries *db.Queries, canceller JobCanceller) (*AdminHandler, error) {
 	return &AdminHandler{
-		queries: queries,
+		queries:   queries,
+		canceller: canceller,
 	}, nil
 }
 
@@ -26,6 +35,8 @@ func (h *AdminHandler) RegisterRoutes(r *mux.Router) {
 	// Admin UI routes
 	r.HandleFunc("/", h.handleAdminIndex).Methods("GET")
 	r.HandleFunc("/webhooks/{id}", h.handleWebhookDetail).Methods("GET")
+	r.HandleFunc("/jobs/{id}", h.handleJobDetail).Methods("GET")
+	r.HandleFunc("/dead-letter", h.handleDeadLetterPage).Methods("GET")
 
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
@@ -42,12 +53,32 @@ func (h *AdminHandler) RegisterRoutes(r *mux.Router) {
 	api.HandleFunc("/webhooks/{id}/keys", h.handleCreateAPIKey).Methods("POST")
 	api.HandleFunc("/keys/{id}", h.handleDeleteAPIKey).Methods("DELETE")
 	
+	// Discord bot channels
+	api.HandleFunc("/webhooks/{id}/discord-channels", h.handleListDiscordChannels).Methods("GET")
+	api.HandleFunc("/webhooks/{id}/discord-channels", h.handleUpdateDiscordChannels).Methods("PUT")
+	
+	// GitHub integration
+	api.HandleFunc("/webhooks/{id}/github", h.handleGetGitHubIntegration).Methods("GET")
+	api.HandleFunc("/webhooks/{id}/github", h.handleUpdateGitHubIntegration).Methods("PUT")
+	api.HandleFunc("/webhooks/{id}/github", h.handleDeleteGitHubIntegration).Methods("DELETE")
+	
 	// Execution history
 	api.HandleFunc("/webhooks/{id}/executions", h.handleListExecutions).Methods("GET")
 	api.HandleFunc("/webhooks/{id}/stats", h.handleGetStats).Methods("GET")
 	
 	// Job queue
 	api.HandleFunc("/webhooks/{id}/queue", h.handleListJobQueue).Methods("GET")
+	api.HandleFunc("/jobs/{id}/stream", h.handleStreamJobEvents).Methods("GET")
+	api.HandleFunc("/jobs/{id}/cancel", h.handleCancelJob).Methods("POST")
+	api.HandleFunc("/jobs/{id}/notifications", h.handleListNotificationDeliveries).Methods("GET")
+	api.HandleFunc("/notifications/{id}/resend", h.handleResendNotificationDelivery).Methods("POST")
+	
+	// Dead-letter queue
+	api.HandleFunc("/dead-letter", h.handleListDeadLetterJobs).Methods("GET")
+	api.HandleFunc("/dead-letter/requeue", h.handleRequeueDeadLetterJobs).Methods("POST")
+	api.HandleFunc("/dead-letter/discard", h.handleDiscardDeadLetterJobs).Methods("POST")
+	api.HandleFunc("/dead-letter/{id}/requeue", h.handleRequeueJob).Methods("POST")
+	api.HandleFunc("/dead-letter/{id}", h.handleDiscardJob).Methods("DELETE")
 	
 	// Security logs
 	api.HandleFunc("/webhooks/{id}/security-logs", h.handleListSecurityLogs).Methods("GET")
@@ -120,22 +151,44 @@ func (h *AdminHandler) handleWebhookDetail(w http.ResponseWriter, r *http.Reques
 		"Model":                    webhook.Model.String,
 		"FallbackModel":            webhook.FallbackModel.String,
 		"MCPServers":               webhook.McpServers.String,
+		"PromptTemplate":           webhook.PromptTemplate.String,
+		"OverridePolicy":           webhook.OverridePolicy.String,
+		"MaxConcurrency":           webhook.MaxConcurrency,
+		"RetryBackoffBaseSeconds":  webhook.RetryBackoffBaseSeconds,
+		"RetryBackoffFactor":       webhook.RetryBackoffFactor,
+		"RetryBackoffJitter":       webhook.RetryBackoffJitter,
+		"RetryBackoffMaxSeconds":   webhook.RetryBackoffMaxSeconds,
+		"DailyBudgetUSD":           formatBudget(webhook.DailyBudgetUsd),
+		"MonthlyBudgetUSD":         formatBudget(webhook.MonthlyBudgetUsd),
 		"NotificationConfig":       "",
-		"DiscordWebhookURL":        "",
 	}
 	
-	// Extract Discord webhook URL from notification config
+	// Extract notifier settings from notification config
+	var notifConfig map[string]interface{}
 	if notifBytes, ok := webhook.NotificationConfig.([]byte); ok {
-		data["NotificationConfig"] = string(notifBytes)
-		
-		var notifConfig map[string]interface{}
-		if err := json.Unmarshal(notifBytes, &notifConfig); err == nil {
-			if discord, ok := notifConfig["discord"].(map[string]interface{}); ok {
-				if webhookURL, ok := discord["webhook_url"].(string); ok {
-					data["DiscordWebhookURL"] = webhookURL
-				}
-			}
+		if redacted, ok := redactNotificationConfig(notifBytes).([]byte); ok {
+			data["NotificationConfig"] = string(redacted)
 		}
+		json.Unmarshal(notifBytes, &notifConfig)
+	}
+	for key, value := range parseNotificationSettings(notifConfig).formData() {
+		data[key] = value
+	}
+
+	channelIDs, err := h.queries.ListDiscordChannels(r.Context(), webhook.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data["DiscordChannelIDs"] = strings.Join(channelIDs, ", ")
+
+	integration, err := h.queries.GetGitHubIntegration(r.Context(), webhook.ID)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for key, value := range gitHubFormData(integration, err == nil) {
+		data[key] = value
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+)
+
+// budgetStatus is the spending of a webhook within one budget period. Periods
+// follow UTC calendar days and months, and a job counts towards the period it
+// was created in.
+type budgetStatus struct {
+	Period   string    `json:"period"`
+	LimitUSD float64   `json:"limit_usd"`
+	SpentUSD float64   `json:"spent_usd"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+func (b budgetStatus) exceeded() bool {
+	return b.SpentUSD >= b.LimitUSD
+}
+
+// budgetExceededError is returned when a webhook has spent its daily or
+// monthly budget and cannot take new jobs until the period resets
+type budgetExceededError struct {
+	budgetStatus
+}
+
+func (e *budgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget of $%.2f for this webhook is spent ($%.4f used); it resets at %s",
+		e.Period, e.LimitUSD, e.SpentUSD, e.ResetsAt.Format(time.RFC3339))
+}
+
+// webhookBudgets returns the spending of the webhook for each budget it has
+func webhookBudgets(ctx context.Context, queries *db.Queries, webhook *db.Webhook, now time.Time) ([]budgetStatus, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	periods := []struct {
+		name  string
+		limit sql.NullFloat64
+		start time.Time
+		end   time.Time
+	}{
+		{"daily", webhook.DailyBudgetUsd, today, today.AddDate(0, 0, 1)},
+		{"monthly", webhook.MonthlyBudgetUsd, month, month.AddDate(0, 1, 0)},
+	}
+
+	var budgets []budgetStatus
+	for _, p := range periods {
+		if !p.limit.Valid {
+			continue
+		}
+		spent, err := queries.GetWebhookCost(ctx, db.GetWebhookCostParams{
+			WebhookID:    webhook.ID,
+			JobCreatedAt: p.start,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s spending: %w", p.name, err)
+		}
+		budgets = append(budgets, budgetStatus{
+			Period:   p.name,
+			LimitUSD: p.limit.Float64,
+			SpentUSD: spent,
+			ResetsAt: p.end,
+		})
+	}
+	return budgets, nil
+}
+
+// checkBudget returns a *budgetExceededError when the webhook has spent one of
+// its budgets. Running jobs only count once they report their cost, so a burst
+// of requests can overshoot a budget by the jobs already queued.
+func checkBudget(ctx context.Context, queries *db.Queries, webhook *db.Webhook) error {
+	budgets, err := webhookBudgets(ctx, queries, webhook, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, b := range budgets {
+		if b.exceeded() {
+			return &budgetExceededError{budgetStatus: b}
+		}
+	}
+	return nil
+}
+
+// enqueueErrorText tells chat users why their job was not enqueued
+func enqueueErrorText(err error) string {
+	var budgetErr *budgetExceededError
+	if errors.As(err, &budgetErr) {
+		return "Job was not enqueued: the " + budgetErr.Error() + "."
+	}
+	return "Failed to enqueue job."
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/database"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+)
+
+// newTestDB opens a fresh database with the schema applied
+func newTestDB(t *testing.T) (*database.DB, *db.Queries) {
+	t.Helper()
+	conn, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	schema, err := os.ReadFile("../../sql/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(string(schema)); err != nil {
+		t.Fatalf("failed to apply schema: %v", err)
+	}
+	return conn, db.New(conn)
+}
+
+// createTestWebhook inserts an active webhook with the schema's defaults
+func createTestWebhook(t *testing.T, conn *database.DB, id string) db.Webhook {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "INSERT INTO webhooks (id, name) VALUES (?, ?)", id, "test-"+id); err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := db.New(conn).GetWebhook(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return webhook
+}
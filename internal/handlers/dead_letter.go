@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/templates"
+)
+
+// deadLetterLimit caps how many failed jobs the dead-letter list returns
+const deadLetterLimit = 100
+
+// deadLetterJob is the JSON representation of a failed job and its attempts
+type deadLetterJob struct {
+	db.ListDeadLetterJobsRow
+	Attempts []db.JobAttempt `json:"attempts"`
+}
+
+// deadLetterFilter reads the optional webhook_id and error_contains filters
+// from the query string or form body
+func deadLetterFilter(r *http.Request) (webhookID, errorContains sql.NullString) {
+	if v := r.FormValue("webhook_id"); v != "" {
+		webhookID = sql.NullString{String: v, Valid: true}
+	}
+	if v := r.FormValue("error_contains"); v != "" {
+		errorContains = sql.NullString{String: v, Valid: true}
+	}
+	return webhookID, errorContains
+}
+
+// handleDeadLetterPage renders the dead-letter page listing failed jobs of all webhooks
+func (h *AdminHandler) handleDeadLetterPage(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.queries.ListWebhooks(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	content, err := templates.GetFile(templates.DeadLetterTemplate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := template.New("dead_letter").Parse(string(content))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Webhooks": webhooks,
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleListDeadLetterJobs returns failed jobs with their retry history
+func (h *AdminHandler) handleListDeadLetterJobs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhookID, errorContains := deadLetterFilter(r)
+
+	jobs, err := h.queries.ListDeadLetterJobs(ctx, db.ListDeadLetterJobsParams{
+		WebhookID:     webhookID,
+		ErrorContains: errorContains,
+		Limit:         deadLetterLimit,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]deadLetterJob, 0, len(jobs))
+	for _, job := range jobs {
+		attempts, err := h.queries.ListJobAttempts(ctx, job.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = append(result, deadLetterJob{ListDeadLetterJobsRow: job, Attempts: attempts})
+	}
+
+	// If it's an HTMX request, return the table rows
+	if r.Header.Get("HX-Request") == "true" {
+		if len(result) == 0 {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<tr><td colspan="6" class="px-6 py-8 text-center text-sm text-gray-500">No failed jobs</td></tr>`))
+			return
+		}
+
+		content, err := templates.GetFile(templates.DeadLetterItemTemplate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tmpl := template.Must(template.New("dead_letter_item").Parse(string(content)))
+
+		var buf bytes.Buffer
+		for _, job := range result {
+			attempts := make([]map[string]interface{}, 0, len(job.Attempts))
+			for _, attempt := range job.Attempts {
+				attempts = append(attempts, map[string]interface{}{
+					"CreatedAt":    attempt.CreatedAt.Format("2006-01-02 15:04:05"),
+					"ErrorMessage": attempt.ErrorMessage,
+					"Retryable":    attempt.Retryable,
+				})
+			}
+
+			data := map[string]interface{}{
+				"ID":           job.ID,
+				"WebhookID":    job.WebhookID,
+				"WebhookName":  job.WebhookName,
+				"Prompt":       job.Prompt,
+				"ErrorMessage": job.ErrorMessage.String,
+				"RetryCount":   job.RetryCount,
+				"MaxRetries":   job.MaxRetries,
+				"CreatedAt":    job.CreatedAt.Format("2006-01-02 15:04:05"),
+				"Attempts":     attempts,
+			}
+			if err := tmpl.Execute(&buf, data); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(buf.Bytes())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleRequeueJob moves a single failed job back to the queue with a fresh retry budget
+func (h *AdminHandler) handleRequeueJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := parseJobID(r)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.queries.RequeueJob(r.Context(), jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job not found or not failed", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// If it's an HTMX request, remove the row from the dead-letter list
+	if r.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleDiscardJob deletes a single failed job
+func (h *AdminHandler) handleDiscardJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := parseJobID(r)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.queries.DiscardJob(r.Context(), jobID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Job not found or not failed", http.StatusNotFound)
+		return
+	}
+
+	// If it's an HTMX request, remove the row from the dead-letter list
+	if r.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRequeueDeadLetterJobs requeues every failed job matching the filter
+func (h *AdminHandler) handleRequeueDeadLetterJobs(w http.ResponseWriter, r *http.Request) {
+	webhookID, errorContains := deadLetterFilter(r)
+
+	n, err := h.queries.RequeueDeadLetterJobs(r.Context(), db.RequeueDeadLetterJobsParams{
+		WebhookID:     webhookID,
+		ErrorContains: errorContains,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeDeadLetterBulkResult(w, r, "Requeued", n)
+}
+
+// handleDiscardDeadLetterJobs deletes every failed job matching the filter
+func (h *AdminHandler) handleDiscardDeadLetterJobs(w http.ResponseWriter, r *http.Request) {
+	webhookID, errorContains := deadLetterFilter(r)
+
+	n, err := h.queries.DiscardDeadLetterJobs(r.Context(), db.DiscardDeadLetterJobsParams{
+		WebhookID:     webhookID,
+		ErrorContains: errorContains,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeDeadLetterBulkResult(w, r, "Discarded", n)
+}
+
+// writeDeadLetterBulkResult reports how many jobs a bulk action affected and
+// tells an HTMX page to reload its dead-letter list
+func writeDeadLetterBulkResult(w http.ResponseWriter, r *http.Request, action string, count int64) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("HX-Trigger", "deadLetterChanged")
+		fmt.Fprintf(w, "%s %d jobs", action, count)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{
+		"count": count,
+	})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+)
+
+// discordChannelsRequest replaces the Discord channels linked to a webhook
+type discordChannelsRequest struct {
+	ChannelIDs []string `json:"channel_ids"`
+}
+
+// parseChannelIDs splits a comma- or newline-separated list of Discord channel IDs
+func parseChannelIDs(s string) ([]string, error) {
+	var ids []string
+	for _, id := range strings.FieldsFunc(s, func(c rune) bool { return c == ',' || c == '\n' || c == ' ' }) {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, validateChannelIDs(ids)
+}
+
+// validateChannelIDs checks that every ID is a Discord snowflake
+func validateChannelIDs(ids []string) error {
+	for _, id := range ids {
+		if strings.Trim(id, "0123456789") != "" {
+			return fmt.Errorf("invalid channel ID %q", id)
+		}
+	}
+	return nil
+}
+
+func (h *AdminHandler) handleListDiscordChannels(w http.ResponseWriter, r *http.Request) {
+	ids, err := h.queries.ListDiscordChannels(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(discordChannelsRequest{ChannelIDs: ids})
+}
+
+// handleUpdateDiscordChannels links the given Discord channels to the webhook so
+// the /claude command run in them enqueues jobs for it
+func (h *AdminHandler) handleUpdateDiscordChannels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhookID := mux.Vars(r)["id"]
+
+	var req discordChannelsRequest
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ids, err := parseChannelIDs(r.FormValue("discord_channel_ids"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.ChannelIDs = ids
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateChannelIDs(req.ChannelIDs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := h.queries.GetWebhook(ctx, webhookID); err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// A channel submits to a single webhook
+	for _, id := range req.ChannelIDs {
+		linked, err := h.queries.GetWebhookByDiscordChannel(ctx, id)
+		if err == nil && linked.ID != webhookID {
+			http.Error(w, fmt.Sprintf("Channel %s is already linked to webhook %q", id, linked.Name), http.StatusConflict)
+			return
+		}
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := h.queries.DeleteDiscordChannels(ctx, webhookID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	seen := map[string]bool{}
+	for _, id := range req.ChannelIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := h.queries.CreateDiscordChannel(ctx, db.CreateDiscordChannelParams{
+			ChannelID: id,
+			WebhookID: webhookID,
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
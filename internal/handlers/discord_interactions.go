@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/discordbot"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// DiscordCommandName is the slash command that submits a prompt
+	DiscordCommandName = "claude"
+	// maxInteractionBodySize bounds the interaction payloads the endpoint reads
+	maxInteractionBodySize = 1 << 20
+	// originalResponseAttempts is how often the message of a command response is looked up
+	originalResponseAttempts = 5
+)
+
+// DiscordCommand is the slash command registered by the discord-register command
+var DiscordCommand = discordbot.Command{
+	Name:        DiscordCommandName,
+	Description: "Run a prompt with Claude Code",
+	Options: []discordbot.CommandOptionDef{
+		{
+			Type:        discordbot.OptionTypeString,
+			Name:        "prompt",
+			Description: "The prompt to run",
+			Required:    true,
+		},
+	},
+}
+
+// DiscordInteractionsHandler is the interactions endpoint of the Discord bot. The
+// /claude command enqueues its prompt for the webhook linked to the channel it was
+// run in, and the worker posts the result to a thread on the command's response.
+type DiscordInteractionsHandler struct {
+	webhooks  *WebhookExecutionHandler
+	publicKey ed25519.PublicKey
+	client    *discordbot.Client
+}
+
+func NewDiscordInteractionsHandler(webhooks *WebhookExecutionHandler, publicKey ed25519.PublicKey, client *discordbot.Client) *DiscordInteractionsHandler {
+	return &DiscordInteractionsHandler{
+		webhooks:  webhooks,
+		publicKey: publicKey,
+		client:    client,
+	}
+}
+
+func (h *DiscordInteractionsHandler) HandleInteraction(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInteractionBodySize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Discord rejects the endpoint unless unsigned requests are refused
+	if !discordbot.Verify(h.publicKey, r.Header.Get("X-Signature-Ed25519"), r.Header.Get("X-Signature-Timestamp"), body) {
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	var interaction discordbot.Interaction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	switch interaction.Type {
+	case discordbot.InteractionTypePing:
+		writeInteractionResponse(w, discordbot.InteractionResponse{Type: discordbot.ResponseTypePong})
+	case discordbot.InteractionTypeApplicationCommand:
+		h.handleCommand(r.Context(), w, &interaction)
+	default:
+		http.Error(w, "Unsupported interaction type", http.StatusBadRequest)
+	}
+}
+
+// handleCommand enqueues the prompt of a /claude command and answers with the job ID
+func (h *DiscordInteractionsHandler) handleCommand(ctx context.Context, w http.ResponseWriter, interaction *discordbot.Interaction) {
+	if interaction.Data == nil || interaction.Data.Name != DiscordCommandName {
+		writeEphemeral(w, "Unknown command.")
+		return
+	}
+
+	prompt := interaction.Data.StringOption("prompt")
+	if prompt == "" {
+		writeEphemeral(w, "Prompt is required.")
+		return
+	}
+
+	webhook, err := h.webhooks.queries.GetWebhookByDiscordChannel(ctx, interaction.ChannelID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeEphemeral(w, "This channel is not linked to a webhook. Add its channel ID to a webhook's Discord settings.")
+			return
+		}
+		slog.ErrorContext(ctx, "Failed to look up webhook of Discord channel", "channel_id", interaction.ChannelID, "error", err)
+		writeEphemeral(w, "Internal server error.")
+		return
+	}
+
+	ctx = logging.With(ctx, "webhook_id", webhook.ID)
+
+	job, err := h.webhooks.enqueueJob(ctx, &webhook, nil, models.WebhookRequest{Prompt: prompt}, func(q *db.Queries, job db.JobQueue) error {
+		if err := q.CreateDiscordReply(ctx, db.CreateDiscordReplyParams{
+			JobID:     job.ID,
+			ChannelID: interaction.ChannelID,
+		}); err != nil {
+			return fmt.Errorf("failed to record Discord reply: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to enqueue Discord command", "error", err)
+		writeEphemeral(w, enqueueErrorText(err))
+		return
+	}
+	ctx = logging.With(ctx, "job_id", job.ID)
+
+	author := "someone"
+	if user := interaction.Author(); user != nil {
+		author = user.Username
+	}
+	writeInteractionResponse(w, discordbot.InteractionResponse{
+		Type: discordbot.ResponseTypeChannelMessageWithSource,
+		Data: &discordbot.ResponseData{
+			Content:         fmt.Sprintf(":hourglass_flowing_sand: Job #%d queued for %s by %s:\n>>> %s", job.ID, webhook.Name, author, notifier.Truncate(prompt, 1500, "")),
+			AllowedMentions: &discordbot.AllowedMentions{Parse: []string{}},
+		},
+	})
+
+	// The response message only exists once Discord has received the response above
+	go h.recordResponseMessage(context.WithoutCancel(ctx), job.ID, interaction.ApplicationID, interaction.Token)
+}
+
+// recordResponseMessage stores the ID of the message that answered a command so
+// the result can be posted to a thread on it
+func (h *DiscordInteractionsHandler) recordResponseMessage(ctx context.Context, jobID int64, applicationID, token string) {
+	var err error
+	for attempt := 1; attempt <= originalResponseAttempts; attempt++ {
+		time.Sleep(time.Duration(attempt) * time.Second)
+
+		var message discordbot.Message
+		if message, err = h.client.GetOriginalResponse(applicationID, token); err != nil {
+			continue
+		}
+		if err = h.webhooks.queries.SetDiscordReplyMessage(ctx, db.SetDiscordReplyMessageParams{
+			MessageID: sql.NullString{String: message.ID, Valid: true},
+			JobID:     jobID,
+		}); err == nil {
+			return
+		}
+	}
+	slog.WarnContext(ctx, "Failed to get the Discord response message, replying in the channel instead", "error", err)
+}
+
+func writeInteractionResponse(w http.ResponseWriter, response discordbot.InteractionResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeEphemeral answers a command with a message only its author can see
+func writeEphemeral(w http.ResponseWriter, content string) {
+	writeInteractionResponse(w, discordbot.InteractionResponse{
+		Type: discordbot.ResponseTypeChannelMessageWithSource,
+		Data: &discordbot.ResponseData{
+			Content: content,
+			Flags:   discordbot.MessageFlagEphemeral,
+		},
+	})
+}
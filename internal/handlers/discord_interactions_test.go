@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/discordbot"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// fakeDiscordAPI records the REST requests of a discordbot.Client and answers
+// lookups of a command's response with message-1
+type fakeDiscordAPI struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *fakeDiscordAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body bytes.Buffer
+	body.ReadFrom(r.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+body.String()))
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/messages/@original") {
+		json.NewEncoder(w).Encode(discordbot.Message{ID: "message-1", ChannelID: "channel-1"})
+		return
+	}
+	w.Write([]byte(`{}`))
+}
+
+func (f *fakeDiscordAPI) recorded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+type discordTest struct {
+	handler    *DiscordInteractionsHandler
+	queries    *db.Queries
+	client     *discordbot.Client
+	api        *fakeDiscordAPI
+	privateKey ed25519.PrivateKey
+}
+
+func newDiscordTest(t *testing.T) *discordTest {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &fakeDiscordAPI{}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	client := discordbot.NewClient(server.URL, "bot-token")
+
+	conn, queries := newTestDB(t)
+	webhook := createTestWebhook(t, conn, "discord")
+	if err := queries.CreateDiscordChannel(context.Background(), db.CreateDiscordChannelParams{
+		ChannelID: "channel-1",
+		WebhookID: webhook.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return &discordTest{
+		handler:    NewDiscordInteractionsHandler(NewWebhookExecutionHandler(queries), publicKey, client),
+		queries:    queries,
+		client:     client,
+		api:        api,
+		privateKey: privateKey,
+	}
+}
+
+// do sends an interaction signed with the test's key
+func (dt *discordTest) do(t *testing.T, interaction discordbot.Interaction) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(interaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := "1700000000"
+	signature := ed25519.Sign(dt.privateKey, append([]byte(timestamp), body...))
+	return dt.send(body, hex.EncodeToString(signature), timestamp)
+}
+
+func (dt *discordTest) send(body []byte, signature, timestamp string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/discord/interactions", bytes.NewReader(body))
+	req.Header.Set("X-Signature-Ed25519", signature)
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	rec := httptest.NewRecorder()
+	dt.handler.HandleInteraction(rec, req)
+	return rec
+}
+
+func decodeInteractionResponse(t *testing.T, rec *httptest.ResponseRecorder) discordbot.InteractionResponse {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var response discordbot.InteractionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid interaction response %q: %v", rec.Body, err)
+	}
+	return response
+}
+
+func claudeCommand(channelID, prompt string) discordbot.Interaction {
+	return discordbot.Interaction{
+		ID:            "interaction-1",
+		ApplicationID: "app-1",
+		Type:          discordbot.InteractionTypeApplicationCommand,
+		ChannelID:     channelID,
+		Token:         "interaction-token",
+		Member:        &discordbot.Member{User: &discordbot.User{ID: "user-1", Username: "alice"}},
+		Data: &discordbot.CommandData{
+			Name:    DiscordCommandName,
+			Options: []discordbot.CommandOption{{Name: "prompt", Type: discordbot.OptionTypeString, Value: prompt}},
+		},
+	}
+}
+
+func TestHandleInteractionPing(t *testing.T) {
+	dt := newDiscordTest(t)
+
+	response := decodeInteractionResponse(t, dt.do(t, discordbot.Interaction{ID: "ping", Type: discordbot.InteractionTypePing}))
+	if response.Type != discordbot.ResponseTypePong {
+		t.Errorf("response type = %d, want PONG", response.Type)
+	}
+}
+
+func TestHandleInteractionRejectsBadSignature(t *testing.T) {
+	dt := newDiscordTest(t)
+	body := []byte(`{"type":1}`)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, signature := range map[string]string{
+		"missing":   "",
+		"not hex":   "zz",
+		"other key": hex.EncodeToString(ed25519.Sign(otherKey, append([]byte("1700000000"), body...))),
+		"tampered":  hex.EncodeToString(ed25519.Sign(dt.privateKey, append([]byte("1700000000"), `{"type":2}`...))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rec := dt.send(body, signature, "1700000000"); rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleInteractionUnlinkedChannel(t *testing.T) {
+	dt := newDiscordTest(t)
+
+	response := decodeInteractionResponse(t, dt.do(t, claudeCommand("channel-2", "hello")))
+	if response.Data == nil || response.Data.Flags != discordbot.MessageFlagEphemeral {
+		t.Fatalf("response = %+v, want an ephemeral message", response)
+	}
+	if !strings.Contains(response.Data.Content, "not linked to a webhook") {
+		t.Errorf("content = %q", response.Data.Content)
+	}
+	if _, err := dt.queries.GetDiscordReply(context.Background(), 1); err != sql.ErrNoRows {
+		t.Errorf("GetDiscordReply error = %v, want no reply", err)
+	}
+}
+
+func TestHandleInteractionClaudeCommand(t *testing.T) {
+	dt := newDiscordTest(t)
+	ctx := context.Background()
+
+	response := decodeInteractionResponse(t, dt.do(t, claudeCommand("channel-1", "Summarize the logs")))
+	if response.Type != discordbot.ResponseTypeChannelMessageWithSource || response.Data == nil {
+		t.Fatalf("response = %+v, want a channel message", response)
+	}
+	if response.Data.Flags&discordbot.MessageFlagEphemeral != 0 {
+		t.Error("command response is ephemeral")
+	}
+	if !strings.Contains(response.Data.Content, "Job #1 queued for test-discord by alice") {
+		t.Errorf("content = %q", response.Data.Content)
+	}
+
+	job, err := dt.queries.GetJobStatus(ctx, 1)
+	if err != nil {
+		t.Fatalf("job was not enqueued: %v", err)
+	}
+	if job.WebhookID != "discord" || job.Prompt != "Summarize the logs" {
+		t.Errorf("job = %+v", job)
+	}
+
+	// The response message is looked up once Discord has received the response
+	var reply db.DiscordReply
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if reply, err = dt.queries.GetDiscordReply(ctx, job.ID); err != nil {
+			t.Fatalf("no Discord reply was recorded: %v", err)
+		}
+		if reply.MessageID.Valid || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if reply.ChannelID != "channel-1" || reply.MessageID.String != "message-1" {
+		t.Fatalf("reply = %+v, want channel-1/message-1", reply)
+	}
+
+	// The result goes to a thread started from the response message
+	notifier := discordbot.NewReplyNotifier(dt.client, discordbot.ReplyConfig{ChannelID: reply.ChannelID, MessageID: reply.MessageID.String})
+	if err := notifier.SendNotification(&models.WebhookResponse{JobID: job.ID, Success: true, Prompt: job.Prompt, Response: "All good"}); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	want := []string{
+		"GET /webhooks/app-1/interaction-token/messages/@original",
+		`POST /channels/channel-1/messages/message-1/threads {"name":"Job #1: Summarize the logs"}`,
+		`POST /channels/message-1/messages {"content":"All good","allowed_mentions":{"parse":[]}}`,
+	}
+	if got := dt.api.recorded(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Discord requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -153,6 +154,31 @@ func (h *AdminHandler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Usage covers every job, including failed attempts and discarded jobs
+	usage, err := h.queries.GetWebhookUsageStats(r.Context(), db.GetWebhookUsageStatsParams{
+		WebhookID:    webhookID,
+		JobCreatedAt: since,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
+	webhook, err := h.queries.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	budgets, err := webhookBudgets(r.Context(), h.queries, &webhook, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
 	// Render HTML for HTMX
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("Content-Type", "text/html")
@@ -183,16 +209,43 @@ func (h *AdminHandler) handleGetStats(w http.ResponseWriter, r *http.Request) {
 					<div class="text-2xl font-bold">%.0fms</div>
 					<div class="text-sm text-gray-600">Avg Execution Time</div>
 				</div>
+				<div>
+					<div class="text-2xl font-bold">$%.2f</div>
+					<div class="text-sm text-gray-600">Total Cost</div>
+				</div>
+				<div>
+					<div class="text-2xl font-bold">%d / %d</div>
+					<div class="text-sm text-gray-600">Input / Output Tokens</div>
+				</div>
+				<div>
+					<div class="text-2xl font-bold">%d</div>
+					<div class="text-sm text-gray-600">Cache Read Tokens</div>
+				</div>
 			</div>
-		</div>
-		`, stats.TotalExecutions, successRate, avgTime)
+		`, stats.TotalExecutions, successRate, avgTime,
+			usage.TotalCostUsd, usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens)
+		
+		for _, b := range budgets {
+			color := "text-gray-600"
+			if b.exceeded() {
+				color = "text-red-600"
+			}
+			fmt.Fprintf(w, `
+			<div class="mt-4 text-sm %s">%s budget: $%.4f of $%.2f spent (resets %s UTC)</div>
+			`, color, strings.ToUpper(b.Period[:1])+b.Period[1:], b.SpentUSD, b.LimitUSD, b.ResetsAt.Format("2006-01-02 15:04"))
+		}
+		fmt.Fprint(w, `</div>`)
 		
 		return
 	}
 	
 	// Return JSON
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	json.NewEncoder(w).Encode(struct {
+		db.GetExecutionStatsRow
+		db.GetWebhookUsageStatsRow
+		Budgets []budgetStatus `json:"budgets,omitempty"`
+	}{stats, usage, budgets})
 }
 
 func escapeHTML(s string) string {
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
+)
+
+// gitHubIntegrationRequest configures the GitHub integration of a webhook. An
+// empty secret keeps the current one.
+type gitHubIntegrationRequest struct {
+	Secret         string   `json:"secret,omitempty"`
+	Events         []string `json:"events"`
+	TriggerPhrase  string   `json:"trigger_phrase"`
+	PromptTemplate string   `json:"prompt_template"`
+	// AllowedAssociations defaults to githubbot.DefaultAllowedAssociations
+	AllowedAssociations []string `json:"allowed_associations,omitempty"`
+}
+
+// gitHubIntegrationResponse is a GitHub integration without its secret
+type gitHubIntegrationResponse struct {
+	Events              []string `json:"events"`
+	TriggerPhrase       string   `json:"trigger_phrase"`
+	PromptTemplate      string   `json:"prompt_template"`
+	AllowedAssociations []string `json:"allowed_associations"`
+}
+
+// gitHubEventOption is an event or author association checkbox of the GitHub integration form
+type gitHubEventOption struct {
+	Name    string
+	Checked bool
+}
+
+// gitHubFormData returns the template data of the GitHub integration form. A
+// webhook without an integration gets the defaults of the schema.
+func gitHubFormData(integration db.GithubIntegration, configured bool) map[string]interface{} {
+	if !configured {
+		integration.Events = githubbot.EventIssueComment
+		integration.TriggerPhrase = "@claude"
+		integration.AllowedAssociations = githubbot.DefaultAllowedAssociations
+	}
+
+	events := strings.Split(integration.Events, ",")
+	options := make([]gitHubEventOption, 0, len(githubbot.Events))
+	for _, name := range githubbot.Events {
+		options = append(options, gitHubEventOption{Name: name, Checked: slices.Contains(events, name)})
+	}
+
+	allowed := strings.Split(integration.AllowedAssociations, ",")
+	associations := make([]gitHubEventOption, 0, len(githubbot.AuthorAssociations))
+	for _, name := range githubbot.AuthorAssociations {
+		associations = append(associations, gitHubEventOption{Name: name, Checked: slices.Contains(allowed, name)})
+	}
+
+	return map[string]interface{}{
+		"GitHubConfigured":      configured,
+		"GitHubEvents":          options,
+		"GitHubAssociations":    associations,
+		"GitHubTriggerPhrase":   integration.TriggerPhrase,
+		"GitHubPromptTemplate":  integration.PromptTemplate,
+		"GitHubDefaultTemplate": githubbot.DefaultPromptTemplate,
+	}
+}
+
+func (h *AdminHandler) handleGetGitHubIntegration(w http.ResponseWriter, r *http.Request) {
+	integration, err := h.queries.GetGitHubIntegration(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(gitHubIntegrationResponse{
+		Events:              strings.Split(integration.Events, ","),
+		TriggerPhrase:       integration.TriggerPhrase,
+		PromptTemplate:      integration.PromptTemplate,
+		AllowedAssociations: strings.Split(integration.AllowedAssociations, ","),
+	})
+}
+
+// handleUpdateGitHubIntegration creates or updates the GitHub integration of a webhook
+func (h *AdminHandler) handleUpdateGitHubIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhookID := mux.Vars(r)["id"]
+
+	var req gitHubIntegrationRequest
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Secret = r.FormValue("github_secret")
+		req.Events = r.Form["github_events"]
+		req.TriggerPhrase = r.FormValue("github_trigger_phrase")
+		req.PromptTemplate = r.FormValue("github_prompt_template")
+		// Unchecking every association is an error rather than the default
+		req.AllowedAssociations = append([]string{}, r.Form["github_allowed_associations"]...)
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if len(req.Events) == 0 {
+		http.Error(w, "At least one event is required", http.StatusBadRequest)
+		return
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(githubbot.Events, event) {
+			http.Error(w, fmt.Sprintf("Unsupported event %q", event), http.StatusBadRequest)
+			return
+		}
+	}
+	allowed := githubbot.DefaultAllowedAssociations
+	if req.AllowedAssociations != nil {
+		if len(req.AllowedAssociations) == 0 {
+			http.Error(w, "At least one author association is required", http.StatusBadRequest)
+			return
+		}
+		for _, association := range req.AllowedAssociations {
+			if !slices.Contains(githubbot.AuthorAssociations, association) {
+				http.Error(w, fmt.Sprintf("Unsupported author association %q", association), http.StatusBadRequest)
+				return
+			}
+		}
+		allowed = strings.Join(req.AllowedAssociations, ",")
+	}
+	if _, err := githubbot.ParsePromptTemplate(req.PromptTemplate); err != nil {
+		http.Error(w, "Invalid prompt template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.GetWebhook(ctx, webhookID); err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		current, err := h.queries.GetGitHubIntegration(ctx, webhookID)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if current.Secret == "" {
+			http.Error(w, "Secret is required", http.StatusBadRequest)
+			return
+		}
+		secret = current.Secret
+	}
+
+	if err := h.queries.UpsertGitHubIntegration(ctx, db.UpsertGitHubIntegrationParams{
+		WebhookID:           webhookID,
+		Secret:              secret,
+		Events:              strings.Join(req.Events, ","),
+		TriggerPhrase:       strings.TrimSpace(req.TriggerPhrase),
+		PromptTemplate:      req.PromptTemplate,
+		AllowedAssociations: allowed,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) handleDeleteGitHubIntegration(w http.ResponseWriter, r *http.Request) {
+	if err := h.queries.DeleteGitHubIntegration(r.Context(), mux.Vars(r)["id"]); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"cmp"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// maxGitHubDeliverySize is the largest payload GitHub sends
+const maxGitHubDeliverySize = 25 << 20
+
+// GitHubWebhookHandler receives GitHub webhook deliveries for a webhook with a
+// GitHub integration. Comments containing the trigger phrase, and optionally
+// newly opened issues and pull requests, enqueue a prompt rendered from the
+// integration's template when their author's association with the repository
+// is allowed, and the result is commented back.
+type GitHubWebhookHandler struct {
+	webhooks *WebhookExecutionHandler
+}
+
+func NewGitHubWebhookHandler(webhooks *WebhookExecutionHandler) *GitHubWebhookHandler {
+	return &GitHubWebhookHandler{webhooks: webhooks}
+}
+
+func (h *GitHubWebhookHandler) HandleDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID := mux.Vars(r)["uuid"]
+	ctx := logging.With(r.Context(), "webhook_id", webhookID)
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGitHubDeliverySize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.webhooks.queries.GetWebhook(ctx, webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	integration, err := h.webhooks.queries.GetGitHubIntegration(ctx, webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "GitHub integration is not configured for this webhook", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !githubbot.Verify(integration.Secret, r.Header.Get("X-Hub-Signature-256"), body) {
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event == githubbot.EventPing {
+		writeDeliveryResult(w, "ok", "pong", 0)
+		return
+	}
+	if !slices.Contains(strings.Split(integration.Events, ","), event) {
+		writeDeliveryResult(w, "ignored", "Event "+event+" is not enabled", 0)
+		return
+	}
+
+	var payload githubbot.Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Results must not trigger another job, whether a bot or a user's token comments them
+	if payload.Sender.IsBot() || (payload.Comment != nil && strings.Contains(payload.Comment.Body, githubbot.CommentMarker)) {
+		writeDeliveryResult(w, "ignored", "Comment was posted by a bot or by this worker", 0)
+		return
+	}
+
+	data, ok := githubbot.NewPromptData(event, &payload)
+	if !ok {
+		writeDeliveryResult(w, "ignored", "Action "+payload.Action+" does not trigger a job", 0)
+		return
+	}
+	if !githubbot.Triggers(data.TriggerText(), integration.TriggerPhrase) {
+		writeDeliveryResult(w, "ignored", "Trigger phrase not found", 0)
+		return
+	}
+	// Anyone can comment on a public repository, so only trusted authors run prompts
+	if !data.AuthorAllowed(integration.AllowedAssociations) {
+		writeDeliveryResult(w, "ignored", "Author @"+data.Author+" ("+cmp.Or(data.AuthorAssociation, "no association")+") is not allowed to trigger a job", 0)
+		return
+	}
+
+	prompt, err := githubbot.RenderPrompt(integration.PromptTemplate, data)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to render GitHub prompt", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	job, err := h.webhooks.enqueueJob(ctx, &webhook, nil, models.WebhookRequest{Prompt: prompt}, func(q *db.Queries, job db.JobQueue) error {
+		if err := q.CreateGitHubReply(ctx, db.CreateGitHubReplyParams{
+			JobID:       job.ID,
+			Repository:  data.Repository,
+			IssueNumber: data.Number,
+		}); err != nil {
+			return fmt.Errorf("failed to record GitHub reply: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		var budgetErr *budgetExceededError
+		if errors.As(err, &budgetErr) {
+			writeDeliveryResult(w, "ignored", budgetErr.Error(), 0)
+			return
+		}
+		slog.ErrorContext(ctx, "Failed to enqueue GitHub delivery", "error", err)
+		http.Error(w, "Failed to enqueue job", http.StatusInternalServerError)
+		return
+	}
+
+	writeDeliveryResult(w, "accepted", "Webhook execution enqueued", job.ID)
+}
+
+// writeDeliveryResult answers a delivery; GitHub shows the response in the
+// webhook's recent deliveries, which helps explain ignored events
+func writeDeliveryResult(w http.ResponseWriter, status, message string, jobID int64) {
+	response := map[string]interface{}{
+		"status":  status,
+		"message": message,
+	}
+	if jobID != 0 {
+		response["job_id"] = jobID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
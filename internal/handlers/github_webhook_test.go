@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/database"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
+)
+
+const testGitHubSecret = "github-secret"
+
+type githubTest struct {
+	handler *GitHubWebhookHandler
+	conn    *database.DB
+	queries *db.Queries
+}
+
+func newGitHubTest(t *testing.T, integration db.UpsertGitHubIntegrationParams) *githubTest {
+	t.Helper()
+	conn, queries := newTestDB(t)
+	webhook := createTestWebhook(t, conn, "github")
+
+	integration.WebhookID = webhook.ID
+	if integration.Secret == "" {
+		integration.Secret = testGitHubSecret
+	}
+	if integration.Events == "" {
+		integration.Events = githubbot.EventIssueComment
+	}
+	if integration.AllowedAssociations == "" {
+		integration.AllowedAssociations = githubbot.DefaultAllowedAssociations
+	}
+	if err := queries.UpsertGitHubIntegration(context.Background(), integration); err != nil {
+		t.Fatal(err)
+	}
+
+	return &githubTest{
+		handler: NewGitHubWebhookHandler(NewWebhookExecutionHandler(queries)),
+		conn:    conn,
+		queries: queries,
+	}
+}
+
+// deliver sends a delivery signed with testGitHubSecret and decodes the result
+func (gt *githubTest) deliver(t *testing.T, event string, payload interface{}) map[string]interface{} {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := gt.send(event, body, signGitHub(testGitHubSecret, body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("invalid delivery result %q: %v", rec.Body, err)
+	}
+	return result
+}
+
+func (gt *githubTest) send(event string, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/github/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", signature)
+	req = mux.SetURLVars(req, map[string]string{"uuid": "github"})
+	rec := httptest.NewRecorder()
+	gt.handler.HandleDelivery(rec, req)
+	return rec
+}
+
+func signGitHub(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// issueComment is an issue_comment delivery for a comment by alice, a member, on issue #7
+func issueComment(action, comment string) githubbot.Payload {
+	return githubbot.Payload{
+		Action: action,
+		Issue: &githubbot.Issue{
+			Number:  7,
+			Title:   "Crash on start",
+			Body:    "It crashes.",
+			HTMLURL: "https://github.com/octo/repo/issues/7",
+			User:    &githubbot.User{Login: "bob", Type: "User"},
+		},
+		Comment: &githubbot.Comment{
+			Body:              comment,
+			HTMLURL:           "https://github.com/octo/repo/issues/7#issuecomment-1",
+			User:              &githubbot.User{Login: "alice", Type: "User"},
+			AuthorAssociation: "MEMBER",
+		},
+		Repository: &githubbot.Repository{FullName: "octo/repo"},
+		Sender:     &githubbot.User{Login: "alice", Type: "User"},
+	}
+}
+
+func TestHandleDeliveryVerifiesSignature(t *testing.T) {
+	gt := newGitHubTest(t, db.UpsertGitHubIntegrationParams{TriggerPhrase: "@claude"})
+	body, _ := json.Marshal(issueComment("created", "@claude fix it"))
+
+	for name, signature := range map[string]string{
+		"missing":        "",
+		"without sha256": hex.EncodeToString([]byte(signGitHub(testGitHubSecret, body))),
+		"other secret":   signGitHub("other-secret", body),
+		"other body":     signGitHub(testGitHubSecret, append(body, ' ')),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rec := gt.send(githubbot.EventIssueComment, body, signature); rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rec.Code)
+			}
+		})
+	}
+	if _, err := gt.queries.GetJobStatus(context.Background(), 1); err == nil {
+		t.Error("an unsigned delivery enqueued a job")
+	}
+}
+
+func TestHandleDeliveryIgnored(t *testing.T) {
+	bot := issueComment("created", "@claude fix it")
+	bot.Sender = &githubbot.User{Login: "ci[bot]", Type: "Bot"}
+	bot.Comment.User = bot.Sender
+	outsider := issueComment("created", "@claude run rm -rf /")
+	outsider.Comment.AuthorAssociation = "NONE"
+	unknown := issueComment("created", "@claude fix it")
+	unknown.Comment.AuthorAssociation = ""
+
+	tests := []struct {
+		name    string
+		event   string
+		payload githubbot.Payload
+		message string
+	}{
+		{"event not enabled", githubbot.EventIssues, githubbot.Payload{Action: "opened"}, "Event issues is not enabled"},
+		{"edited comment", githubbot.EventIssueComment, issueComment("edited", "@claude fix it"), "Action edited does not trigger a job"},
+		{"deleted comment", githubbot.EventIssueComment, issueComment("deleted", "@claude fix it"), "Action deleted does not trigger a job"},
+		{"no trigger phrase", githubbot.EventIssueComment, issueComment("created", "Looks good to me"), "Trigger phrase not found"},
+		{"bot", githubbot.EventIssueComment, bot, "Comment was posted by a bot or by this worker"},
+		{"author not allowed", githubbot.EventIssueComment, outsider, "Author @alice (NONE) is not allowed to trigger a job"},
+		{"author association missing", githubbot.EventIssueComment, unknown, "Author @alice (no association) is not allowed to trigger a job"},
+		{"result comment", githubbot.EventIssueComment, issueComment("created", githubbot.CommentMarker+"\n@claude said"), "Comment was posted by a bot or by this worker"},
+	}
+
+	gt := newGitHubTest(t, db.UpsertGitHubIntegrationParams{TriggerPhrase: "@claude"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := gt.deliver(t, tt.event, tt.payload)
+			if result["status"] != "ignored" || result["message"] != tt.message {
+				t.Errorf("result = %v, want ignored: %s", result, tt.message)
+			}
+		})
+	}
+	if _, err := gt.queries.GetJobStatus(context.Background(), 1); err == nil {
+		t.Error("an ignored delivery enqueued a job")
+	}
+}
+
+func TestHandleDeliveryPing(t *testing.T) {
+	gt := newGitHubTest(t, db.UpsertGitHubIntegrationParams{})
+
+	if result := gt.deliver(t, githubbot.EventPing, map[string]string{"zen": "Keep it simple."}); result["status"] != "ok" {
+		t.Errorf("result = %v, want ok", result)
+	}
+}
+
+func TestHandleDeliveryEnqueuesComment(t *testing.T) {
+	gt := newGitHubTest(t, db.UpsertGitHubIntegrationParams{TriggerPhrase: "@Claude"})
+	ctx := context.Background()
+
+	result := gt.deliver(t, githubbot.EventIssueComment, issueComment("created", "@claude please fix this"))
+	if result["status"] != "accepted" || result["job_id"] != float64(1) {
+		t.Fatalf("result = %v, want job 1 accepted", result)
+	}
+
+	job, err := gt.queries.GetJobStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Issue #7 in octo/repo: Crash on start
+https://github.com/octo/repo/issues/7#issuecomment-1
+
+It crashes.
+
+Comment by @alice:
+@claude please fix this`
+	if job.Prompt != want {
+		t.Errorf("prompt =\n%s\nwant:\n%s", job.Prompt, want)
+	}
+
+	reply, err := gt.queries.GetGitHubReply(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("no GitHub reply was recorded: %v", err)
+	}
+	if reply.Repository != "octo/repo" || reply.IssueNumber != 7 {
+		t.Errorf("reply = %+v, want octo/repo#7", reply)
+	}
+}
+
+func TestHandleDeliveryWithoutReplyEnqueuesNothing(t *testing.T) {
+	gt := newGitHubTest(t, db.UpsertGitHubIntegrationParams{TriggerPhrase: "@Claude"})
+	ctx := context.Background()
+
+	// A job whose result could not be commented must not run
+	if _, err := gt.conn.ExecContext(ctx, "DROP TABLE github_replies"); err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(issueComment("created", "@claude please fix this"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := gt.send(githubbot.EventIssueComment, body, signGitHub(testGitHubSecret, body))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	var jobs int
+	if err := gt.conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM job_queue").Scan(&jobs); err != nil {
+		t.Fatal(err)
+	}
+	if jobs != 0 {
+		t.Errorf("%d jobs were enqueued, want none", jobs)
+	}
+}
+
+func TestHandleDeliveryRendersTemplate(t *testing.T) {
+	gt := newGitHubTest(t, db.UpsertGitHubIntegrationParams{
+		Events:              githubbot.EventPullRequest,
+		PromptTemplate:      "Review {{.Repository}}#{{.Number}} ({{.Event}}/{{.Action}}) by {{.Author}} ({{.AuthorAssociation}}): {{.Title}}",
+		AllowedAssociations: "OWNER,CONTRIBUTOR",
+	})
+
+	result := gt.deliver(t, githubbot.EventPullRequest, githubbot.Payload{
+		Action: "opened",
+		PullRequest: &githubbot.Issue{
+			Number:            12,
+			Title:             "Add retries",
+			User:              &githubbot.User{Login: "carol", Type: "User"},
+			AuthorAssociation: "CONTRIBUTOR",
+		},
+		Repository: &githubbot.Repository{FullName: "octo/repo"},
+		Sender:     &githubbot.User{Login: "carol", Type: "User"},
+	})
+	if result["status"] != "accepted" {
+		t.Fatalf("result = %v, want accepted", result)
+	}
+
+	job, err := gt.queries.GetJobStatus(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Review octo/repo#12 (pull_request/opened) by carol (CONTRIBUTOR): Add retries"; job.Prompt != want {
+		t.Errorf("prompt = %q, want %q", job.Prompt, want)
+	}
+}
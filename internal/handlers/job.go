@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/templates"
+)
+
+// jobStreamPollInterval is how often the SSE stream checks for new job events
+const jobStreamPollInterval = 1 * time.Second
+
+// isTerminalJobStatus reports whether a job will not produce any more events
+func isTerminalJobStatus(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+func parseJobID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+}
+
+// handleJobDetail renders the job detail page with a live event log
+func (h *AdminHandler) handleJobDetail(w http.ResponseWriter, r *http.Request) {
+	jobID, err := parseJobID(r)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.queries.GetJobStatus(r.Context(), jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	content, err := templates.GetFile(templates.JobDetailTemplate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := template.New("job_detail").Parse(string(content))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := map[string]interface{}{
+		"ID":           job.ID,
+		"WebhookID":    job.WebhookID,
+		"Status":       job.JobStatus,
+		"Prompt":       job.Prompt,
+		"RetryCount":   job.RetryCount,
+		"MaxRetries":   job.MaxRetries,
+		"CreatedAt":    job.CreatedAt.Format("2006-01-02 15:04:05"),
+		"StartedAt":    "",
+		"CompletedAt":  "",
+		"Response":     job.Response.String,
+		"ErrorMessage": job.ErrorMessage.String,
+		"CallbackURL":  job.CallbackUrl.String,
+		// Set when the prompt was rendered from the webhook's prompt template
+		"PromptTemplate":  job.PromptTemplate.String,
+		"PromptVariables": job.PromptVariables.String,
+		"Thread":          job.Thread.String,
+		"ResumeSessionID": job.ResumeSessionID.String,
+		"SessionID":       job.SessionID.String,
+		// Usage of every attempt of the job
+		"TotalCostUSD": job.TotalCostUsd,
+		"InputTokens":  job.InputTokens,
+		"OutputTokens": job.OutputTokens,
+		"NumTurns":     job.NumTurns,
+	}
+	if job.StartedAt.Valid {
+		data["StartedAt"] = job.StartedAt.Time.Format("2006-01-02 15:04:05")
+	}
+	if job.CompletedAt.Valid {
+		data["CompletedAt"] = job.CompletedAt.Time.Format("2006-01-02 15:04:05")
+	}
+
+	deliveries, err := h.queries.ListCallbackDeliveries(r.Context(), jobID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	callbackDeliveries := make([]map[string]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		callbackDeliveries = append(callbackDeliveries, map[string]interface{}{
+			"Attempt":      delivery.Attempt,
+			"StatusCode":   delivery.StatusCode.Int64,
+			"ErrorMessage": delivery.ErrorMessage.String,
+			"Success":      delivery.Success,
+			"CreatedAt":    delivery.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	data["CallbackDeliveries"] = callbackDeliveries
+
+	w.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleStreamJobEvents streams job events as Server-Sent Events until the job finishes
+func (h *AdminHandler) handleStreamJobEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	jobID, err := parseJobID(r)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.queries.GetJobStatus(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Resume from the last event the client received
+	var lastEventID int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			lastEventID = parsed
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(jobStreamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := h.queries.ListJobEventsAfter(ctx, db.ListJobEventsAfterParams{
+			JobID: jobID,
+			ID:    lastEventID,
+		})
+		if err != nil {
+			fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+			flusher.Flush()
+			return
+		}
+
+		for _, event := range events {
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, event.Payload)
+			lastEventID = event.ID
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+		}
+
+		// Only finish once every event written before completion has been sent
+		if isTerminalJobStatus(job.JobStatus) && len(events) == 0 {
+			fmt.Fprintf(w, "event: done\ndata: %q\n\n", job.JobStatus)
+			flusher.Flush()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		job, err = h.queries.GetJobStatus(ctx, jobID)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleCancelJob cancels a pending or running job
+func (h *AdminHandler) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	jobID, err := parseJobID(r)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.GetJobStatus(ctx, jobID); err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	job, err := h.canceller.CancelJob(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Job is not pending or running", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// If it's an HTMX request, return the updated job row
+	if r.Header.Get("HX-Request") == "true" {
+		content, err := templates.GetFile(templates.JobQueueItemTemplate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tmpl := template.Must(template.New("queue").Parse(string(content)))
+
+		w.Header().Set("Content-Type", "text/html")
+		if err := tmpl.Execute(w, jobQueueItemData(job)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
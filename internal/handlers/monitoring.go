@@ -123,6 +123,7 @@ func (h *AdminHandler) handleListJobQueue(w http.ResponseWriter, r *http.Request
 							<th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Created</th>
 							<th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Started</th>
 							<th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Completed</th>
+							<th class="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">Actions</th>
 						</tr>
 					</thead>
 					<tbody class="bg-white divide-y divide-gray-200">
@@ -137,18 +138,8 @@ func (h *AdminHandler) handleListJobQueue(w http.ResponseWriter, r *http.Request
 	tmpl := template.Must(template.New("queue").Parse(string(content)))
 	
 	for _, job := range jobs {
-		data := map[string]interface{}{
-			"ID":          job.ID,
-			"Status":      job.JobStatus,
-			"Prompt":      job.Prompt,
-			"Priority":    job.Priority,
-			"CreatedAt":   job.CreatedAt.Format("15:04:05"),
-			"StartedAt":   job.StartedAt,
-			"CompletedAt": job.CompletedAt,
-		}
-		
 		var buf bytes.Buffer
-		if err := tmpl.Execute(&buf, data); err != nil {
+		if err := tmpl.Execute(&buf, jobQueueItemData(job)); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -165,4 +156,17 @@ func (h *AdminHandler) handleListJobQueue(w http.ResponseWriter, r *http.Request
 			</div>
 		</div>
 	`))
-}
\ No newline at end of file
+}
+
+// jobQueueItemData builds the template data for a single job queue row
+func jobQueueItemData(job db.JobQueue) map[string]interface{} {
+	return map[string]interface{}{
+		"ID":          job.ID,
+		"Status":      job.JobStatus,
+		"Prompt":      job.Prompt,
+		"Priority":    job.Priority,
+		"CreatedAt":   job.CreatedAt.Format("15:04:05"),
+		"StartedAt":   job.StartedAt,
+		"CompletedAt": job.CompletedAt,
+	}
+}
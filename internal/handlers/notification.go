@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/discord"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/email"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/generichttp"
+)
+
+// notificationSettings is the form and JSON view of a notification_config,
+// used both for webhooks and for the global default
+type notificationSettings struct {
+	DiscordWebhookURL string `json:"discord_webhook_url"`
+	// DiscordLongResponse is how Discord sends long responses: truncate, split or attachment
+	DiscordLongResponse string              `json:"discord_long_response,omitempty"`
+	SlackWebhookURL     string              `json:"slack_webhook_url"`
+	Email               *email.Config       `json:"email,omitempty"`
+	GenericHTTP         *generichttp.Config `json:"generic_http,omitempty"`
+	// Rules holds the event rules of each notifier, keyed by its notification_config key
+	Rules map[string]notifier.Rules `json:"rules,omitempty"`
+	// EmailPasswordSet reports a stored SMTP password, which is never shown
+	EmailPasswordSet bool `json:"email_password_set,omitempty"`
+}
+
+// notifierRuleRows lists the notifiers in the order the rules form shows them
+var notifierRuleRows = []struct {
+	Name  string
+	Label string
+}{
+	{"discord", "Discord"},
+	{"slack", "Slack"},
+	{"email", "Email"},
+	{"generic_http", "Generic HTTP"},
+}
+
+// notificationRuleRow is one notifier's row of the rules form
+type notificationRuleRow struct {
+	Name               string
+	Label              string
+	Events             string
+	OnlyOnFailure      bool
+	MinDurationSeconds string
+}
+
+// parseNotificationSettings extracts the settings of every notifier from a decoded notification_config
+func parseNotificationSettings(notifConfig map[string]interface{}) notificationSettings {
+	var settings notificationSettings
+	if discord, ok := notifConfig["discord"].(map[string]interface{}); ok {
+		if url, ok := discord["webhook_url"].(string); ok {
+			settings.DiscordWebhookURL = url
+		}
+		if mode, ok := discord["long_response"].(string); ok {
+			settings.DiscordLongResponse = mode
+		}
+	}
+	if slack, ok := notifConfig["slack"].(map[string]interface{}); ok {
+		if url, ok := slack["webhook_url"].(string); ok {
+			settings.SlackWebhookURL = url
+		}
+	}
+	if raw, ok := notifConfig["email"].(map[string]interface{}); ok {
+		if emailConfig, err := email.ParseConfig(raw); err == nil {
+			settings.Email = &emailConfig
+		}
+	}
+	if raw, ok := notifConfig["generic_http"].(map[string]interface{}); ok {
+		if httpConfig, err := generichttp.ParseConfig(raw); err == nil {
+			settings.GenericHTTP = &httpConfig
+		}
+	}
+	for _, row := range notifierRuleRows {
+		raw, ok := notifConfig[row.Name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if rules, err := notifier.ParseRules(raw); err == nil && !rules.IsZero() {
+			if settings.Rules == nil {
+				settings.Rules = map[string]notifier.Rules{}
+			}
+			settings.Rules[row.Name] = rules
+		}
+	}
+	return settings
+}
+
+// notificationSettingsFromForm reads the notifier fields of the webhook and settings forms.
+// The email and generic HTTP notifiers are only configured when a host or URL is given.
+func notificationSettingsFromForm(r *http.Request) (notificationSettings, error) {
+	settings := notificationSettings{
+		DiscordWebhookURL:   r.FormValue("discord_webhook_url"),
+		DiscordLongResponse: r.FormValue("discord_long_response"),
+		SlackWebhookURL:     r.FormValue("slack_webhook_url"),
+	}
+
+	if host := r.FormValue("email_host"); host != "" {
+		emailConfig := &email.Config{
+			Host:     host,
+			StartTLS: r.FormValue("email_starttls") != "",
+			Username: r.FormValue("email_username"),
+			Password: r.FormValue("email_password"),
+			From:     r.FormValue("email_from"),
+			To:       email.ParseRecipients(r.FormValue("email_to")),
+		}
+		if val := r.FormValue("email_port"); val != "" {
+			port, err := strconv.Atoi(val)
+			if err != nil {
+				return settings, fmt.Errorf("invalid port %q", val)
+			}
+			emailConfig.Port = port
+		}
+		settings.Email = emailConfig
+	}
+
+	if url := r.FormValue("generic_http_url"); url != "" {
+		headers, err := generichttp.ParseHeaders(r.FormValue("generic_http_headers"))
+		if err != nil {
+			return settings, fmt.Errorf("generic_http: %w", err)
+		}
+		settings.GenericHTTP = &generichttp.Config{
+			URL:     url,
+			Method:  r.FormValue("generic_http_method"),
+			Headers: headers,
+			Body:    r.FormValue("generic_http_body"),
+		}
+	}
+
+	for _, row := range notifierRuleRows {
+		rules := notifier.Rules{
+			Events:        notifier.ParseEvents(r.FormValue(row.Name + "_events")),
+			OnlyOnFailure: r.FormValue(row.Name+"_only_on_failure") != "",
+		}
+		if val := r.FormValue(row.Name + "_min_duration_seconds"); val != "" {
+			seconds, err := strconv.Atoi(val)
+			if err != nil {
+				return settings, fmt.Errorf("%s: invalid minimum duration %q", row.Name, val)
+			}
+			rules.MinDurationSeconds = seconds
+		}
+		if !rules.IsZero() {
+			if settings.Rules == nil {
+				settings.Rules = map[string]notifier.Rules{}
+			}
+			settings.Rules[row.Name] = rules
+		}
+	}
+
+	return settings, settings.validate()
+}
+
+// validate checks the settings of notifiers that need more than a URL
+func (s notificationSettings) validate() error {
+	if err := (discord.Config{LongResponse: s.DiscordLongResponse}).Validate(); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+	if s.Email != nil {
+		if err := s.Email.Validate(); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+	}
+	if s.GenericHTTP != nil {
+		if err := s.GenericHTTP.Validate(); err != nil {
+			return fmt.Errorf("generic_http: %w", err)
+		}
+	}
+	for name, rules := range s.Rules {
+		if err := rules.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// config builds the notification_config, leaving out notifiers that are not configured
+func (s notificationSettings) config() map[string]interface{} {
+	notifConfig := map[string]interface{}{}
+	if s.DiscordWebhookURL != "" {
+		notifConfig["discord"] = discord.Config{
+			WebhookURL:   s.DiscordWebhookURL,
+			LongResponse: s.DiscordLongResponse,
+		}
+	}
+	if s.SlackWebhookURL != "" {
+		notifConfig["slack"] = map[string]string{
+			"webhook_url": s.SlackWebhookURL,
+		}
+	}
+	if s.Email != nil {
+		notifConfig["email"] = s.Email
+	}
+	if s.GenericHTTP != nil {
+		notifConfig["generic_http"] = s.GenericHTTP
+	}
+	for name, entry := range notifConfig {
+		if rules, ok := s.Rules[name]; ok && !rules.IsZero() {
+			notifConfig[name] = withRules(entry, rules)
+		}
+	}
+	return notifConfig
+}
+
+// withRules adds event rules next to the settings of a notification_config entry
+func withRules(entry interface{}, rules notifier.Rules) interface{} {
+	merged := map[string]interface{}{}
+	for _, v := range []interface{}{entry, rules} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return entry
+		}
+		if err := json.Unmarshal(b, &merged); err != nil {
+			return entry
+		}
+	}
+	return merged
+}
+
+// formData flattens the settings into the values of the notification form fields
+func (s notificationSettings) formData() map[string]interface{} {
+	data := map[string]interface{}{
+		"DiscordWebhookURL":   s.DiscordWebhookURL,
+		"DiscordLongResponse": s.DiscordLongResponse,
+		"SlackWebhookURL":     s.SlackWebhookURL,
+		"EmailHost":           "",
+		"EmailPort":           "",
+		"EmailStartTLS":       false,
+		"EmailUsername":       "",
+		"EmailPasswordSet":    false,
+		"EmailFrom":           "",
+		"EmailTo":             "",
+		"GenericHTTPURL":      "",
+		"GenericHTTPMethod":   "POST",
+		"GenericHTTPHeaders":  "",
+		"GenericHTTPBody":     "",
+	}
+	if s.Email != nil {
+		data["EmailHost"] = s.Email.Host
+		if s.Email.Port != 0 {
+			data["EmailPort"] = strconv.Itoa(s.Email.Port)
+		}
+		data["EmailStartTLS"] = s.Email.StartTLS
+		data["EmailUsername"] = s.Email.Username
+		data["EmailPasswordSet"] = s.Email.Password != ""
+		data["EmailFrom"] = s.Email.From
+		data["EmailTo"] = strings.Join(s.Email.To, ", ")
+	}
+	if s.GenericHTTP != nil {
+		data["GenericHTTPURL"] = s.GenericHTTP.URL
+		if s.GenericHTTP.Method != "" {
+			data["GenericHTTPMethod"] = strings.ToUpper(s.GenericHTTP.Method)
+		}
+		data["GenericHTTPHeaders"] = generichttp.FormatHeaders(s.GenericHTTP.Headers)
+		data["GenericHTTPBody"] = s.GenericHTTP.Body
+	}
+
+	var rows []notificationRuleRow
+	for _, row := range notifierRuleRows {
+		rules := s.Rules[row.Name]
+		ruleRow := notificationRuleRow{
+			Name:          row.Name,
+			Label:         row.Label,
+			Events:        strings.Join(rules.Events, ", "),
+			OnlyOnFailure: rules.OnlyOnFailure,
+		}
+		if rules.MinDurationSeconds != 0 {
+			ruleRow.MinDurationSeconds = strconv.Itoa(rules.MinDurationSeconds)
+		}
+		rows = append(rows, ruleRow)
+	}
+	data["NotificationRules"] = rows
+	data["NotificationEvents"] = strings.Join(notifier.Events, ", ")
+	return data
+}
+
+// redacted returns the settings without the SMTP password, which is only
+// reported as set
+func (s notificationSettings) redacted() notificationSettings {
+	if s.Email != nil && s.Email.Password != "" {
+		emailConfig := *s.Email
+		emailConfig.Password = ""
+		s.Email = &emailConfig
+		s.EmailPasswordSet = true
+	}
+	return s
+}
+
+// parseNotificationConfig decodes a stored notification_config, returning nil if it is invalid
+func parseNotificationConfig(value interface{}) map[string]interface{} {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return nil
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil
+	}
+	return config
+}
+
+// redactNotificationConfig replaces the SMTP password of a stored
+// notification_config by "password_set", keeping the type it is stored as
+func redactNotificationConfig(value interface{}) interface{} {
+	config := parseNotificationConfig(value)
+	emailEntry, ok := config["email"].(map[string]interface{})
+	if !ok {
+		return value
+	}
+	if password, _ := emailEntry["password"].(string); password == "" {
+		return value
+	}
+	delete(emailEntry, "password")
+	emailEntry["password_set"] = true
+
+	redacted, err := json.Marshal(config)
+	if err != nil {
+		return nil
+	}
+	if _, ok := value.(string); ok {
+		return string(redacted)
+	}
+	return redacted
+}
+
+// keepEmailPassword copies the stored SMTP password into a new notification_config
+// whose email entry has none. Forms and API responses never show the password,
+// so an empty one keeps it.
+func keepEmailPassword(config []byte, stored interface{}) []byte {
+	storedEmail, _ := parseNotificationConfig(stored)["email"].(map[string]interface{})
+	password, _ := storedEmail["password"].(string)
+	if password == "" {
+		return config
+	}
+
+	var notifConfig map[string]interface{}
+	if err := json.Unmarshal(config, &notifConfig); err != nil {
+		return config
+	}
+	emailEntry, ok := notifConfig["email"].(map[string]interface{})
+	if !ok {
+		return config
+	}
+	if current, _ := emailEntry["password"].(string); current != "" {
+		return config
+	}
+	delete(emailEntry, "password_set")
+	emailEntry["password"] = password
+
+	merged, err := json.Marshal(notifConfig)
+	if err != nil {
+		return config
+	}
+	return merged
+}
+
+// notificationConfigFromForm builds a webhook's notification_config from the form fields
+func notificationConfigFromForm(r *http.Request) (json.RawMessage, error) {
+	settings, err := notificationSettingsFromForm(r)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(settings.config())
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(jsonBytes), nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/templates"
+)
+
+// notificationDeliveryResponse is the JSON representation of a notification delivery.
+// The notifier config is left out because it may contain credentials.
+type notificationDeliveryResponse struct {
+	ID            int64   `json:"id"`
+	JobID         int64   `json:"job_id"`
+	Notifier      string  `json:"notifier"`
+	Status        string  `json:"status"`
+	Attempts      int64   `json:"attempts"`
+	MaxAttempts   int64   `json:"max_attempts"`
+	NextAttemptAt *string `json:"next_attempt_at,omitempty"`
+	LastError     string  `json:"last_error,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+	DeliveredAt   *string `json:"delivered_at,omitempty"`
+}
+
+func newNotificationDeliveryResponse(delivery db.NotificationDelivery) notificationDeliveryResponse {
+	resp := notificationDeliveryResponse{
+		ID:          delivery.ID,
+		JobID:       delivery.JobID,
+		Notifier:    delivery.Notifier,
+		Status:      delivery.Status,
+		Attempts:    delivery.Attempts,
+		MaxAttempts: delivery.MaxAttempts,
+		LastError:   delivery.LastError.String,
+		CreatedAt:   delivery.CreatedAt.UTC().Format(time.RFC3339),
+		DeliveredAt: formatNullTime(delivery.DeliveredAt),
+	}
+	if delivery.Status == "pending" {
+		resp.NextAttemptAt = formatNullTime(sql.NullTime{Time: delivery.NextAttemptAt, Valid: true})
+	}
+	return resp
+}
+
+// notificationDeliveryItemData builds the template data of a notification delivery row
+func notificationDeliveryItemData(delivery db.NotificationDelivery) map[string]interface{} {
+	data := map[string]interface{}{
+		"ID":            delivery.ID,
+		"Notifier":      delivery.Notifier,
+		"Status":        delivery.Status,
+		"Attempts":      delivery.Attempts,
+		"MaxAttempts":   delivery.MaxAttempts,
+		"LastError":     delivery.LastError.String,
+		"CreatedAt":     delivery.CreatedAt.Format("2006-01-02 15:04:05"),
+		"NextAttemptAt": "",
+		"DeliveredAt":   "",
+		"CanResend":     delivery.Status == "delivered" || delivery.Status == "failed",
+	}
+	if delivery.Status == "pending" {
+		data["NextAttemptAt"] = delivery.NextAttemptAt.Format("2006-01-02 15:04:05")
+	}
+	if delivery.DeliveredAt.Valid {
+		data["DeliveredAt"] = delivery.DeliveredAt.Time.Format("2006-01-02 15:04:05")
+	}
+	return data
+}
+
+// handleListNotificationDeliveries returns the notifications queued for a job
+func (h *AdminHandler) handleListNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	jobID, err := parseJobID(r)
+	if err != nil {
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := h.queries.ListNotificationDeliveries(r.Context(), jobID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// If it's an HTMX request, return the table rows
+	if r.Header.Get("HX-Request") == "true" {
+		if len(deliveries) == 0 {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<tr><td colspan="5" class="px-6 py-4 text-sm text-gray-500">No notifications queued</td></tr>`))
+			return
+		}
+
+		content, err := templates.GetFile(templates.NotificationDeliveryItemTemplate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tmpl := template.Must(template.New("notification_delivery_item").Parse(string(content)))
+
+		var buf bytes.Buffer
+		for _, delivery := range deliveries {
+			if err := tmpl.Execute(&buf, notificationDeliveryItemData(delivery)); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(buf.Bytes())
+		return
+	}
+
+	result := make([]notificationDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, newNotificationDeliveryResponse(delivery))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleResendNotificationDelivery queues a delivered or failed notification again
+func (h *AdminHandler) handleResendNotificationDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deliveryID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.GetNotificationDelivery(ctx, deliveryID); err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	delivery, err := h.queries.ResendNotificationDelivery(ctx, deliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Notification is already queued", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// If it's an HTMX request, return the updated row
+	if r.Header.Get("HX-Request") == "true" {
+		content, err := templates.GetFile(templates.NotificationDeliveryItemTemplate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tmpl := template.Must(template.New("notification_delivery_item").Parse(string(content)))
+
+		w.Header().Set("Content-Type", "text/html")
+		if err := tmpl.Execute(w, notificationDeliveryItemData(delivery)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newNotificationDeliveryResponse(delivery))
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const testEmailConfig = `{"email":{"host":"smtp.example.com","username":"worker","password":"hunter2","from":"worker@example.com","to":["alice@example.com"]}}`
+
+func TestWebhookEmailPasswordIsNeverShown(t *testing.T) {
+	conn, queries := newTestDB(t)
+	createTestWebhook(t, conn, "mail")
+	if _, err := conn.Exec("UPDATE webhooks SET notification_config = ? WHERE id = 'mail'", testEmailConfig); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := NewAdminHandler(queries, nil)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/webhooks/mail", nil), map[string]string{"id": "mail"})
+	rec := httptest.NewRecorder()
+	h.handleGetWebhook(rec, req)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "hunter2") {
+		t.Fatalf("status = %d, body = %s; want the webhook without its password", rec.Code, rec.Body)
+	}
+
+	webhook, err := queries.GetWebhook(context.Background(), "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := parseNotificationSettings(parseNotificationConfig(webhook.NotificationConfig)).formData()
+	if data["EmailPasswordSet"] != true {
+		t.Errorf("EmailPasswordSet = %v, want true", data["EmailPasswordSet"])
+	}
+	for key, value := range data {
+		if value == "hunter2" {
+			t.Errorf("form field %s shows the password", key)
+		}
+	}
+}
+
+func TestUpdateWebhookKeepsEmailPassword(t *testing.T) {
+	conn, queries := newTestDB(t)
+	createTestWebhook(t, conn, "mail")
+	if _, err := conn.Exec("UPDATE webhooks SET notification_config = ? WHERE id = 'mail'", testEmailConfig); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := NewAdminHandler(queries, nil)
+
+	// The form posts an empty password field
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":                "mail",
+		"notification_config": json.RawMessage(`{"email":{"host":"smtp.example.com","username":"worker","from":"worker@example.com","to":["bob@example.com"]}}`),
+	})
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/api/webhooks/mail", bytes.NewReader(body)), map[string]string{"id": "mail"})
+	rec := httptest.NewRecorder()
+	h.handleUpdateWebhook(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	webhook, err := queries.GetWebhook(context.Background(), "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := parseNotificationSettings(parseNotificationConfig(webhook.NotificationConfig))
+	if settings.Email == nil || settings.Email.Password != "hunter2" || settings.Email.To[0] != "bob@example.com" {
+		t.Errorf("email settings = %+v, want the new recipient and the stored password", settings.Email)
+	}
+}
+
+func TestGlobalSettingsEmailPassword(t *testing.T) {
+	conn, queries := newTestDB(t)
+	if _, err := conn.Exec("INSERT INTO global_settings (setting_key, setting_value) VALUES ('default_notification_config', ?)", testEmailConfig); err != nil {
+		t.Fatal(err)
+	}
+	h, _ := NewAdminHandler(queries, nil)
+
+	rec := httptest.NewRecorder()
+	h.handleGetSettings(rec, httptest.NewRequest(http.MethodGet, "/api/settings", nil))
+	var settings notificationSettings
+	if err := json.Unmarshal(rec.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("invalid settings %q: %v", rec.Body, err)
+	}
+	if !settings.EmailPasswordSet || settings.Email == nil || settings.Email.Password != "" {
+		t.Errorf("settings = %s, want the email password reported as set but not shown", rec.Body)
+	}
+
+	form := url.Values{
+		"email_host":     {"smtp.example.com"},
+		"email_password": {""},
+		"email_from":     {"worker@example.com"},
+		"email_to":       {"bob@example.com"},
+	}
+	req := httptest.NewRequest(http.MethodPut, "/api/settings", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.handleUpdateSettings(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	stored, err := queries.GetGlobalSetting(context.Background(), "default_notification_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parseNotificationSettings(parseNotificationConfig(stored)); got.Email == nil || got.Email.Password != "hunter2" {
+		t.Errorf("stored email settings = %+v, want the stored password kept", got.Email)
+	}
+}
@@ -10,10 +10,6 @@ import (
 	"github.com/upamune/claude-code-pull-worker/internal/templates"
 )
 
-type globalSettings struct {
-	DiscordWebhookURL string `json:"discord_webhook_url"`
-}
-
 func (h *AdminHandler) handleGetSettings(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	
@@ -25,7 +21,7 @@ func (h *AdminHandler) handleGetSettings(w http.ResponseWriter, r *http.Request)
 	}
 	
 	
-	// Parse notification config to get Discord URL
+	// Parse notification config to get the notifier settings
 	var notifConfig map[string]interface{}
 	notifBytes, ok := notifValue.([]byte)
 	if !ok {
@@ -42,12 +38,7 @@ func (h *AdminHandler) handleGetSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
-	discordURL := ""
-	if discord, ok := notifConfig["discord"].(map[string]interface{}); ok {
-		if url, ok := discord["webhook_url"].(string); ok {
-			discordURL = url
-		}
-	}
+	settings := parseNotificationSettings(notifConfig)
 	
 	// Render HTML for HTMX
 	if r.Header.Get("HX-Request") == "true" {
@@ -59,11 +50,7 @@ func (h *AdminHandler) handleGetSettings(w http.ResponseWriter, r *http.Request)
 		}
 		tmpl := template.Must(template.New("settings").Parse(string(content)))
 		
-		data := map[string]interface{}{
-			"DiscordWebhookURL": discordURL,
-		}
-		
-		if err := tmpl.Execute(&buf, data); err != nil {
+		if err := tmpl.Execute(&buf, settings.formData()); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -75,13 +62,11 @@ func (h *AdminHandler) handleGetSettings(w http.ResponseWriter, r *http.Request)
 	
 	// Return JSON
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(globalSettings{
-		DiscordWebhookURL: discordURL,
-	})
+	json.NewEncoder(w).Encode(settings.redacted())
 }
 
 func (h *AdminHandler) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
-	var settings globalSettings
+	var settings notificationSettings
 	
 	// Handle form data
 	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
@@ -90,26 +75,34 @@ func (h *AdminHandler) handleUpdateSettings(w http.ResponseWriter, r *http.Reque
 			return
 		}
 		
-		settings.DiscordWebhookURL = r.FormValue("discord_webhook_url")
+		var err error
+		if settings, err = notificationSettingsFromForm(r); err != nil {
+			http.Error(w, "Invalid notification settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 	} else {
 		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		if err := settings.validate(); err != nil {
+			http.Error(w, "Invalid notification settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 	
 	// Update notification config
-	notifConfig := map[string]interface{}{
-		"discord": map[string]string{
-			"webhook_url": settings.DiscordWebhookURL,
-		},
+	notifJSON, err := json.Marshal(settings.config())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-	
-	notifJSON, err := json.Marshal(notifConfig)
+	stored, err := h.queries.GetGlobalSetting(r.Context(), "default_notification_config")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	notifJSON = keepEmailPassword(notifJSON, stored)
 	
 	if err := h.queries.UpdateGlobalSetting(r.Context(), db.UpdateGlobalSettingParams{
 		SettingValue: notifJSON,
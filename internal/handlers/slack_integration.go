@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/slack"
+	"github.com/upamune/claude-code-pull-worker/internal/slackbot"
+)
+
+// mentionPattern matches user mentions such as <@U012AB3CD> in app_mention text
+var mentionPattern = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+
+// SlackIntegrationHandler receives slash commands and app mentions from a Slack app
+// and enqueues their text as a prompt for the webhook in the request URL. Results
+// are posted back through the command's response_url or in the mention's thread.
+type SlackIntegrationHandler struct {
+	webhooks      *WebhookExecutionHandler
+	signingSecret string
+	client        *slackbot.Client
+}
+
+func NewSlackIntegrationHandler(webhooks *WebhookExecutionHandler, signingSecret string, client *slackbot.Client) *SlackIntegrationHandler {
+	return &SlackIntegrationHandler{
+		webhooks:      webhooks,
+		signingSecret: signingSecret,
+		client:        client,
+	}
+}
+
+func (h *SlackIntegrationHandler) HandleSlack(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInteractionBodySize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !slackbot.Verify(h.signingSecret, r.Header.Get("X-Slack-Signature"), r.Header.Get("X-Slack-Request-Timestamp"), body, time.Now()) {
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	webhook, err := h.webhooks.queries.GetWebhook(r.Context(), mux.Vars(r)["uuid"])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := logging.With(r.Context(), "webhook_id", webhook.ID)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		h.handleCommand(ctx, w, &webhook, body)
+		return
+	}
+	h.handleEvent(ctx, w, r, &webhook, body)
+}
+
+// handleCommand enqueues the text of a slash command. Slack expects the answer
+// within three seconds, so the result is posted later through the response_url.
+func (h *SlackIntegrationHandler) handleCommand(ctx context.Context, w http.ResponseWriter, webhook *db.Webhook, body []byte) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	prompt := strings.TrimSpace(form.Get("text"))
+	if prompt == "" {
+		writeSlackMessage(w, slackbot.Message{
+			Text:         fmt.Sprintf("Usage: %s <prompt>", form.Get("command")),
+			ResponseType: slackbot.ResponseTypeEphemeral,
+		})
+		return
+	}
+
+	job, err := h.webhooks.enqueueJob(ctx, webhook, nil, models.WebhookRequest{Prompt: prompt}, func(q *db.Queries, job db.JobQueue) error {
+		responseURL := form.Get("response_url")
+		if responseURL == "" {
+			return nil
+		}
+		if err := q.CreateSlackReply(ctx, db.CreateSlackReplyParams{
+			JobID:       job.ID,
+			ResponseUrl: sql.NullString{String: responseURL, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to record Slack reply: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to enqueue Slack command", "error", err)
+		writeSlackMessage(w, slackbot.Message{
+			Text:         enqueueErrorText(err),
+			ResponseType: slackbot.ResponseTypeEphemeral,
+		})
+		return
+	}
+
+	writeSlackMessage(w, slackbot.Message{
+		Text:         queuedText(job.ID, webhook.Name, "<@"+form.Get("user_id")+">", prompt),
+		ResponseType: slackbot.ResponseTypeInChannel,
+	})
+}
+
+// handleEvent answers the URL verification of the Events API and enqueues the
+// text of app mentions, whose results are posted in the mention's thread
+func (h *SlackIntegrationHandler) handleEvent(ctx context.Context, w http.ResponseWriter, r *http.Request, webhook *db.Webhook, body []byte) {
+	var payload slackbot.EventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if payload.Type == slackbot.PayloadTypeURLVerification {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(payload.Challenge))
+		return
+	}
+
+	// Slack redelivers events it did not see acknowledged in time; the first
+	// delivery has already been enqueued
+	if r.Header.Get("X-Slack-Retry-Num") != "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event := payload.Event
+	if payload.Type != slackbot.PayloadTypeEventCallback || event == nil || event.Type != slackbot.EventTypeAppMention || event.BotID != "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	prompt := strings.TrimSpace(mentionPattern.ReplaceAllString(event.Text, ""))
+	if prompt == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	threadTS := event.ThreadTS
+	if threadTS == "" {
+		threadTS = event.TS
+	}
+	job, err := h.webhooks.enqueueJob(ctx, webhook, nil, models.WebhookRequest{Prompt: prompt}, func(q *db.Queries, job db.JobQueue) error {
+		if err := q.CreateSlackReply(ctx, db.CreateSlackReplyParams{
+			JobID:     job.ID,
+			ChannelID: sql.NullString{String: event.Channel, Valid: true},
+			ThreadTs:  sql.NullString{String: threadTS, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to record Slack reply: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to enqueue Slack mention", "error", err)
+		http.Error(w, "Failed to enqueue job", http.StatusInternalServerError)
+		return
+	}
+	ctx = logging.With(ctx, "job_id", job.ID)
+
+	if h.client.HasToken() {
+		message := slackbot.Message{
+			Channel:  event.Channel,
+			ThreadTS: threadTS,
+			Text:     queuedText(job.ID, webhook.Name, "<@"+event.User+">", prompt),
+		}
+		go func() {
+			if err := h.client.PostMessage(message); err != nil {
+				slog.ErrorContext(ctx, "Failed to acknowledge Slack mention", "error", err)
+			}
+		}()
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// queuedText is the message that acknowledges a submitted prompt
+func queuedText(jobID int64, webhookName, author, prompt string) string {
+	var quoted bytes.Buffer
+	for _, line := range strings.Split(slack.TruncateEscaped(slack.Escape(prompt), 1500, ""), "\n") {
+		quoted.WriteString("\n>" + line)
+	}
+	return fmt.Sprintf(":hourglass_flowing_sand: Job #%d queued for %s by %s:%s", jobID, slack.Escape(webhookName), author, quoted.String())
+}
+
+func writeSlackMessage(w http.ResponseWriter, message slackbot.Message) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
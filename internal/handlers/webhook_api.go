@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/upamune/claude-code-pull-worker/internal/db"
 	"github.com/upamune/claude-code-pull-worker/internal/templates"
+	"github.com/upamune/claude-code-pull-worker/internal/types"
 )
 
 type createWebhookRequest struct {
@@ -33,6 +36,122 @@ type createWebhookRequest struct {
 	MCPServers               string          `json:"mcp_servers"`
 	EnableContinue           bool            `json:"enable_continue"`
 	ContinueMinutes          int             `json:"continue_minutes"`
+	MaxConcurrency           int             `json:"max_concurrency"`
+	RetryBackoffBaseSeconds  *int            `json:"retry_backoff_base_seconds"`
+	RetryBackoffFactor       *float64        `json:"retry_backoff_factor"`
+	RetryBackoffJitter       *float64        `json:"retry_backoff_jitter"`
+	RetryBackoffMaxSeconds   *int            `json:"retry_backoff_max_seconds"`
+	PromptTemplate           string          `json:"prompt_template"`
+	OverridePolicy           string          `json:"override_policy"`
+	DailyBudgetUSD           *float64        `json:"daily_budget_usd"`
+	MonthlyBudgetUSD         *float64        `json:"monthly_budget_usd"`
+}
+
+// Retry backoff defaults, matching the column defaults of the webhooks table
+const (
+	defaultRetryBackoffBaseSeconds = 30
+	defaultRetryBackoffFactor      = 2.0
+	defaultRetryBackoffJitter      = 0.2
+	defaultRetryBackoffMaxSeconds  = 3600
+)
+
+// normalizeRetryBackoff fills in unset retry backoff settings and validates them
+func (req *createWebhookRequest) normalizeRetryBackoff() error {
+	if req.RetryBackoffBaseSeconds == nil {
+		v := defaultRetryBackoffBaseSeconds
+		req.RetryBackoffBaseSeconds = &v
+	}
+	if req.RetryBackoffFactor == nil {
+		v := defaultRetryBackoffFactor
+		req.RetryBackoffFactor = &v
+	}
+	if req.RetryBackoffJitter == nil {
+		v := defaultRetryBackoffJitter
+		req.RetryBackoffJitter = &v
+	}
+	if req.RetryBackoffMaxSeconds == nil {
+		v := defaultRetryBackoffMaxSeconds
+		req.RetryBackoffMaxSeconds = &v
+	}
+
+	if *req.RetryBackoffBaseSeconds < 0 {
+		return fmt.Errorf("retry_backoff_base_seconds must not be negative")
+	}
+	if *req.RetryBackoffFactor < 1 {
+		return fmt.Errorf("retry_backoff_factor must be at least 1")
+	}
+	if *req.RetryBackoffJitter < 0 || *req.RetryBackoffJitter > 1 {
+		return fmt.Errorf("retry_backoff_jitter must be between 0 and 1")
+	}
+	if *req.RetryBackoffMaxSeconds < *req.RetryBackoffBaseSeconds {
+		return fmt.Errorf("retry_backoff_max_seconds must not be less than retry_backoff_base_seconds")
+	}
+	return nil
+}
+
+// parseRetryBackoffForm reads the retry backoff settings of the webhook form
+func (req *createWebhookRequest) parseRetryBackoffForm(r *http.Request) {
+	if val := r.FormValue("retry_backoff_base_seconds"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			req.RetryBackoffBaseSeconds = &n
+		}
+	}
+	if val := r.FormValue("retry_backoff_factor"); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			req.RetryBackoffFactor = &f
+		}
+	}
+	if val := r.FormValue("retry_backoff_jitter"); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			req.RetryBackoffJitter = &f
+		}
+	}
+	if val := r.FormValue("retry_backoff_max_seconds"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			req.RetryBackoffMaxSeconds = &n
+		}
+	}
+}
+
+// parseBudgetForm reads the budgets of the webhook form; an empty field means no budget
+func (req *createWebhookRequest) parseBudgetForm(r *http.Request) {
+	if val := r.FormValue("daily_budget_usd"); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			req.DailyBudgetUSD = &f
+		}
+	}
+	if val := r.FormValue("monthly_budget_usd"); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			req.MonthlyBudgetUSD = &f
+		}
+	}
+}
+
+// validateBudgets rejects negative budgets
+func (req *createWebhookRequest) validateBudgets() error {
+	if req.DailyBudgetUSD != nil && *req.DailyBudgetUSD < 0 {
+		return fmt.Errorf("daily_budget_usd must not be negative")
+	}
+	if req.MonthlyBudgetUSD != nil && *req.MonthlyBudgetUSD < 0 {
+		return fmt.Errorf("monthly_budget_usd must not be negative")
+	}
+	return nil
+}
+
+// nullBudget converts an optional budget to its column value
+func nullBudget(budget *float64) sql.NullFloat64 {
+	if budget == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *budget, Valid: true}
+}
+
+// formatBudget formats a budget for the webhook form, leaving it empty when unset
+func formatBudget(budget sql.NullFloat64) string {
+	if !budget.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(budget.Float64, 'f', -1, 64)
 }
 
 func (h *AdminHandler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
@@ -106,6 +225,8 @@ func (h *AdminHandler) handleCreateWebhook(w http.ResponseWriter, r *http.Reques
 		req.Model = r.FormValue("model")
 		req.FallbackModel = r.FormValue("fallback_model")
 		req.MCPServers = r.FormValue("mcp_servers")
+		req.PromptTemplate = r.FormValue("prompt_template")
+		req.OverridePolicy = r.FormValue("override_policy")
 		
 		// Parse boolean enable_continue field
 		req.EnableContinue = r.FormValue("enable_continue") == "true"
@@ -128,21 +249,21 @@ func (h *AdminHandler) handleCreateWebhook(w http.ResponseWriter, r *http.Reques
 		} else {
 			req.ContinueMinutes = 10 // デフォルト値
 		}
-		
-		// Handle Discord webhook URL
-		discordWebhookURL := r.FormValue("discord_webhook_url")
-		if discordWebhookURL != "" {
-			notifConfig := map[string]interface{}{
-				"discord": map[string]string{
-					"webhook_url": discordWebhookURL,
-				},
+		if val := r.FormValue("max_concurrency"); val != "" {
+			if n, err := strconv.Atoi(val); err == nil {
+				req.MaxConcurrency = n
 			}
-			jsonBytes, _ := json.Marshal(notifConfig)
-			req.NotificationConfig = json.RawMessage(jsonBytes)
-		} else {
-			// Use empty JSON if no webhook URL provided
-			req.NotificationConfig = json.RawMessage("{}")
 		}
+		req.parseRetryBackoffForm(r)
+		req.parseBudgetForm(r)
+		
+		// Handle notification webhook URLs
+		notifConfig, err := notificationConfigFromForm(r)
+		if err != nil {
+			http.Error(w, "Invalid notification settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.NotificationConfig = notifConfig
 	} else {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -150,6 +271,40 @@ func (h *AdminHandler) handleCreateWebhook(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	// A webhook runs at most one job at a time unless configured otherwise
+	if req.MaxConcurrency < 1 {
+		req.MaxConcurrency = 1
+	}
+
+	if err := req.normalizeRetryBackoff(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := req.validateBudgets(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Reject MCP server configs that could not be passed to Claude at execution time
+	if _, err := types.ParseMCPServers(req.MCPServers); err != nil {
+		http.Error(w, "Invalid mcp_servers: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.PromptTemplate) != "" {
+		if _, err := types.ParsePromptTemplate(req.PromptTemplate); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Reject override policies that requests could not be checked against
+	if _, err := types.ParseOverridePolicy(req.OverridePolicy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Generate UUID
 	id := uuid.New().String()
 
@@ -183,6 +338,15 @@ func (h *AdminHandler) handleCreateWebhook(w http.ResponseWriter, r *http.Reques
 		McpServers:               sql.NullString{String: req.MCPServers, Valid: req.MCPServers != ""},
 		EnableContinue:           req.EnableContinue,
 		ContinueMinutes:          int64(req.ContinueMinutes),
+		MaxConcurrency:           int64(req.MaxConcurrency),
+		RetryBackoffBaseSeconds:  int64(*req.RetryBackoffBaseSeconds),
+		RetryBackoffFactor:       *req.RetryBackoffFactor,
+		RetryBackoffJitter:       *req.RetryBackoffJitter,
+		RetryBackoffMaxSeconds:   int64(*req.RetryBackoffMaxSeconds),
+		PromptTemplate:           sql.NullString{String: req.PromptTemplate, Valid: strings.TrimSpace(req.PromptTemplate) != ""},
+		OverridePolicy:           sql.NullString{String: req.OverridePolicy, Valid: strings.TrimSpace(req.OverridePolicy) != ""},
+		DailyBudgetUsd:           nullBudget(req.DailyBudgetUSD),
+		MonthlyBudgetUsd:         nullBudget(req.MonthlyBudgetUSD),
 	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -196,6 +360,7 @@ func (h *AdminHandler) handleCreateWebhook(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Otherwise return JSON
+	webhook.NotificationConfig = redactNotificationConfig(webhook.NotificationConfig)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(webhook)
 }
@@ -212,6 +377,7 @@ func (h *AdminHandler) handleGetWebhook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	webhook.NotificationConfig = redactNotificationConfig(webhook.NotificationConfig)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(webhook)
 }
@@ -239,6 +405,8 @@ func (h *AdminHandler) handleUpdateWebhook(w http.ResponseWriter, r *http.Reques
 		req.Model = r.FormValue("model")
 		req.FallbackModel = r.FormValue("fallback_model")
 		req.MCPServers = r.FormValue("mcp_servers")
+		req.PromptTemplate = r.FormValue("prompt_template")
+		req.OverridePolicy = r.FormValue("override_policy")
 		
 		// Parse boolean enable_continue field
 		req.EnableContinue = r.FormValue("enable_continue") == "true"
@@ -261,21 +429,21 @@ func (h *AdminHandler) handleUpdateWebhook(w http.ResponseWriter, r *http.Reques
 		} else {
 			req.ContinueMinutes = 10 // デフォルト値
 		}
-		
-		// Handle Discord webhook URL
-		discordWebhookURL := r.FormValue("discord_webhook_url")
-		if discordWebhookURL != "" {
-			notifConfig := map[string]interface{}{
-				"discord": map[string]string{
-					"webhook_url": discordWebhookURL,
-				},
+		if val := r.FormValue("max_concurrency"); val != "" {
+			if n, err := strconv.Atoi(val); err == nil {
+				req.MaxConcurrency = n
 			}
-			jsonBytes, _ := json.Marshal(notifConfig)
-			req.NotificationConfig = json.RawMessage(jsonBytes)
-		} else {
-			// Use empty JSON if no webhook URL provided
-			req.NotificationConfig = json.RawMessage("{}")
 		}
+		req.parseRetryBackoffForm(r)
+		req.parseBudgetForm(r)
+		
+		// Handle notification webhook URLs
+		notifConfig, err := notificationConfigFromForm(r)
+		if err != nil {
+			http.Error(w, "Invalid notification settings: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.NotificationConfig = notifConfig
 	} else {
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -283,7 +451,53 @@ func (h *AdminHandler) handleUpdateWebhook(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	err := h.queries.UpdateWebhook(r.Context(), db.UpdateWebhookParams{
+	// A webhook runs at most one job at a time unless configured otherwise
+	if req.MaxConcurrency < 1 {
+		req.MaxConcurrency = 1
+	}
+
+	if err := req.normalizeRetryBackoff(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := req.validateBudgets(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Reject MCP server configs that could not be passed to Claude at execution time
+	if _, err := types.ParseMCPServers(req.MCPServers); err != nil {
+		http.Error(w, "Invalid mcp_servers: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.PromptTemplate) != "" {
+		if _, err := types.ParsePromptTemplate(req.PromptTemplate); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Reject override policies that requests could not be checked against
+	if _, err := types.ParseOverridePolicy(req.OverridePolicy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The SMTP password is never shown, so an empty one keeps the stored password
+	current, err := h.queries.GetWebhook(r.Context(), vars["id"])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	req.NotificationConfig = keepEmailPassword(req.NotificationConfig, current.NotificationConfig)
+
+	err = h.queries.UpdateWebhook(r.Context(), db.UpdateWebhookParams{
 		Name:                     req.Name,
 		Description:              sql.NullString{String: req.Description, Valid: req.Description != ""},
 		NotificationConfig:       req.NotificationConfig,
@@ -311,6 +525,15 @@ func (h *AdminHandler) handleUpdateWebhook(w http.ResponseWriter, r *http.Reques
 		McpServers:               sql.NullString{String: req.MCPServers, Valid: req.MCPServers != ""},
 		EnableContinue:           req.EnableContinue,
 		ContinueMinutes:          int64(req.ContinueMinutes),
+		MaxConcurrency:           int64(req.MaxConcurrency),
+		RetryBackoffBaseSeconds:  int64(*req.RetryBackoffBaseSeconds),
+		RetryBackoffFactor:       *req.RetryBackoffFactor,
+		RetryBackoffJitter:       *req.RetryBackoffJitter,
+		RetryBackoffMaxSeconds:   int64(*req.RetryBackoffMaxSeconds),
+		PromptTemplate:           sql.NullString{String: req.PromptTemplate, Valid: strings.TrimSpace(req.PromptTemplate) != ""},
+		OverridePolicy:           sql.NullString{String: req.OverridePolicy, Valid: strings.TrimSpace(req.OverridePolicy) != ""},
+		DailyBudgetUsd:           nullBudget(req.DailyBudgetUSD),
+		MonthlyBudgetUsd:         nullBudget(req.MonthlyBudgetUSD),
 		ID:                       vars["id"],
 	})
 	if err != nil {
@@ -336,4 +559,4 @@ func (h *AdminHandler) handleDeleteWebhook(w http.ResponseWriter, r *http.Reques
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
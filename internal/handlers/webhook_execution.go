@@ -4,13 +4,39 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/metrics"
 	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/tracing"
+	"github.com/upamune/claude-code-pull-worker/internal/types"
+	"github.com/upamune/claude-code-pull-worker/internal/worker"
+)
+
+const (
+	// maxWebhookWait caps how long a caller can keep a webhook request open
+	maxWebhookWait = 10 * time.Minute
+	// webhookWaitPollInterval is how often a waiting request checks the job status
+	webhookWaitPollInterval = 500 * time.Millisecond
+	// maxThreadLength caps the name of a conversation thread
+	maxThreadLength = 200
 )
 
 type WebhookExecutionHandler struct {
@@ -56,27 +82,16 @@ func truncateAPIKey(apiKey string) string {
 	return apiKey[:10] + "..."
 }
 
-func (h *WebhookExecutionHandler) HandleWebhookExecution(w http.ResponseWriter, r *http.Request) {
+// authenticate verifies the Bearer API key of a request against the webhook's keys.
+// Webhooks without keys are open. On failure it writes the error response and returns false.
+func (h *WebhookExecutionHandler) authenticate(w http.ResponseWriter, r *http.Request, webhookID string) (*int64, bool) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
-	webhookID := vars["uuid"]
-	
-	// Get webhook
-	webhook, err := h.queries.GetWebhook(ctx, webhookID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			http.NotFound(w, r)
-			return
-		}
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
 	
 	// Get all API keys for this webhook
 	fullKeys, err := h.queries.GetAPIKeysForWebhook(ctx, webhookID)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 	
 	// If webhook has API keys configured, authentication is required
@@ -100,9 +115,10 @@ func (h *WebhookExecutionHandler) HandleWebhookExecution(w http.ResponseWriter,
 				ErrorMessage:   sql.NullString{String: "Authorization header with Bearer token required but not provided", Valid: true},
 				RequestPath:    sql.NullString{String: r.URL.Path, Valid: true},
 			})
+			metrics.AuthFailures.WithLabelValues(webhookID, "missing_api_key").Inc()
 			w.Header().Set("WWW-Authenticate", "Bearer")
 			http.Error(w, "Authorization required", http.StatusUnauthorized)
-			return
+			return nil, false
 		}
 		
 		// Check each key by verifying the provided API key against stored hashes
@@ -130,9 +146,43 @@ func (h *WebhookExecutionHandler) HandleWebhookExecution(w http.ResponseWriter,
 				ErrorMessage:   sql.NullString{String: "Invalid API key provided", Valid: true},
 				RequestPath:    sql.NullString{String: r.URL.Path, Valid: true},
 			})
+			metrics.AuthFailures.WithLabelValues(webhookID, "invalid_api_key").Inc()
 			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return nil, false
+		}
+	}
+	
+	return apiKeyID, true
+}
+
+func (h *WebhookExecutionHandler) HandleWebhookExecution(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["uuid"]
+	
+	// Start the job's trace, continuing the caller's if it sent a traceparent header
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := tracing.Tracer.Start(ctx, "HandleWebhookExecution",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("webhook.id", webhookID)),
+	)
+	defer span.End()
+	ctx = logging.With(ctx, "webhook_id", webhookID)
+	r = r.WithContext(ctx)
+	
+	// Get webhook
+	webhook, err := h.queries.GetWebhook(ctx, webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
 			return
 		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	
+	apiKeyID, ok := h.authenticate(w, r, webhookID)
+	if !ok {
+		return
 	}
 	
 	// Parse request
@@ -142,14 +192,169 @@ func (h *WebhookExecutionHandler) HandleWebhookExecution(w http.ResponseWriter,
 		return
 	}
 	
+	if req.Variables != nil || (req.Prompt == "" && webhook.PromptTemplate.Valid) {
+		if err := renderPrompt(&webhook, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	
 	if req.Prompt == "" {
 		http.Error(w, "Prompt is required", http.StatusBadRequest)
 		return
 	}
 	
-	// Enqueue the job with Claude options from webhook
-	job, err := h.queries.EnqueueJob(ctx, db.EnqueueJobParams{
-		WebhookID:     webhookID,
+	wait, err := waitTimeout(r, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	if req.CallbackURL != "" {
+		if u, err := url.Parse(req.CallbackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, "callback_url must be an absolute http(s) URL", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	policy, err := types.ParseOverridePolicy(webhook.OverridePolicy.String)
+	if err != nil {
+		http.Error(w, "Invalid override_policy on webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := policy.Check(req.Options); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	if err := h.checkSession(ctx, &webhook, policy, req); err != nil {
+		if errors.Is(err, errInvalidSession) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	
+	job, err := h.enqueueJob(ctx, &webhook, apiKeyID, req, nil)
+	if err != nil {
+		var budgetErr *budgetExceededError
+		if errors.As(err, &budgetErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(budgetErr.ResetsAt).Seconds())+1))
+			http.Error(w, budgetErr.Error(), http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, "Failed to enqueue job", http.StatusInternalServerError)
+		return
+	}
+	
+	// Keep the request open until the job finishes if the caller asked for it
+	if wait > 0 {
+		h.waitForJob(w, r, job.ID, wait)
+		return
+	}
+	
+	// Return 200 with job information
+	response := map[string]interface{}{
+		"status":  "accepted",
+		"message": "Webhook execution enqueued",
+		"job_id":  job.ID,
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// renderPrompt sets the prompt of a request to the webhook's prompt template
+// rendered with the request's variables
+func renderPrompt(webhook *db.Webhook, req *models.WebhookRequest) error {
+	if !webhook.PromptTemplate.Valid {
+		return errors.New("variables require a webhook with a prompt_template")
+	}
+	if req.Prompt != "" {
+		return errors.New("send either a prompt or variables, not both")
+	}
+	if req.Variables == nil {
+		req.Variables = map[string]interface{}{}
+	}
+	
+	prompt, err := types.RenderPromptTemplate(webhook.PromptTemplate.String, req.Variables)
+	if err != nil {
+		return err
+	}
+	req.Prompt = prompt
+	return nil
+}
+
+// errInvalidSession wraps the reasons a request cannot resume the session it names
+var errInvalidSession = errors.New("invalid session")
+
+// checkSession verifies the session_id or thread of a request. Resuming a
+// session needs the resume permission of the webhook's override policy, and a
+// session_id must belong to an earlier job of the same webhook, so callers
+// cannot resume conversations of other webhooks.
+func (h *WebhookExecutionHandler) checkSession(ctx context.Context, webhook *db.Webhook, policy *types.OverridePolicy, req models.WebhookRequest) error {
+	if req.SessionID == "" && req.Thread == "" {
+		return nil
+	}
+	if !policy.Resume {
+		return fmt.Errorf("%w: this webhook does not allow resuming sessions with session_id or thread", errInvalidSession)
+	}
+	if req.SessionID != "" && req.Thread != "" {
+		return fmt.Errorf("%w: send either a session_id or a thread, not both", errInvalidSession)
+	}
+	
+	if req.Thread != "" {
+		if len(req.Thread) > maxThreadLength {
+			return fmt.Errorf("%w: thread must be at most %d bytes", errInvalidSession, maxThreadLength)
+		}
+		if strings.TrimSpace(req.Thread) != req.Thread || strings.ContainsFunc(req.Thread, unicode.IsControl) {
+			return fmt.Errorf("%w: thread must not contain control characters or surrounding spaces", errInvalidSession)
+		}
+	}
+	
+	if req.SessionID != "" {
+		exists, err := h.queries.HasWebhookSession(ctx, db.HasWebhookSessionParams{
+			WebhookID: webhook.ID,
+			SessionID: sql.NullString{String: req.SessionID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: unknown session_id %q", errInvalidSession, req.SessionID)
+		}
+	}
+	return nil
+}
+
+// enqueueJob adds a job for the webhook with the webhook's Claude options and
+// queues its "enqueued" notifications. A prompt rendered from the prompt template
+// is stored with the template and the variables it was rendered from, and the
+// span context of ctx so the worker continues the trace. The request's option
+// overrides must already have been checked against the webhook's override
+// policy, and its session against checkSession. A webhook that has spent its
+// budget takes no jobs and a *budgetExceededError is returned. record, unless
+// nil, runs in the transaction that adds the job to store what belongs to it,
+// such as where to reply with its result; the job is not added if it fails.
+func (h *WebhookExecutionHandler) enqueueJob(ctx context.Context, webhook *db.Webhook, apiKeyID *int64, req models.WebhookRequest, record func(q *db.Queries, job db.JobQueue) error) (db.JobQueue, error) {
+	if err := checkBudget(ctx, h.queries, webhook); err != nil {
+		return db.JobQueue{}, err
+	}
+	
+	var promptTemplate, promptVariables sql.NullString
+	if req.Variables != nil {
+		variables, err := json.Marshal(req.Variables)
+		if err != nil {
+			return db.JobQueue{}, err
+		}
+		promptTemplate = webhook.PromptTemplate
+		promptVariables = sql.NullString{String: string(variables), Valid: true}
+	}
+	
+	params := db.EnqueueJobParams{
+		WebhookID:     webhook.ID,
 		ApiKeyID:      func() sql.NullInt64 {
 			if apiKeyID != nil {
 				return sql.NullInt64{Int64: *apiKeyID, Valid: true}
@@ -172,22 +377,215 @@ func (h *WebhookExecutionHandler) HandleWebhookExecution(w http.ResponseWriter,
 		McpServers:               webhook.McpServers,
 		EnableContinue:           webhook.EnableContinue,
 		ContinueMinutes:          webhook.ContinueMinutes,
+		CallbackUrl:              sql.NullString{String: req.CallbackURL, Valid: req.CallbackURL != ""},
+		CallbackSecret:           sql.NullString{String: req.CallbackSecret, Valid: req.CallbackSecret != ""},
+		PromptTemplate:           promptTemplate,
+		PromptVariables:          promptVariables,
+		ResumeSessionID:          sql.NullString{String: req.SessionID, Valid: req.SessionID != ""},
+		Thread:                   sql.NullString{String: req.Thread, Valid: req.Thread != ""},
+		TraceContext:             tracing.Inject(ctx),
+		RequestID:                sql.NullString{String: logging.RequestID(ctx), Valid: logging.RequestID(ctx) != ""},
+	}
+	applyOptionOverrides(&params, req.Options)
+	
+	// The "enqueued" notifications are queued with the job, so neither exists without the other
+	var job db.JobQueue
+	err := h.queries.InTx(ctx, func(q *db.Queries) error {
+		var err error
+		if job, err = q.EnqueueJob(ctx, params); err != nil {
+			return err
+		}
+		if record != nil {
+			if err := record(q, job); err != nil {
+				return err
+			}
+		}
+		return worker.NotifyJobEnqueued(logging.With(ctx, "job_id", job.ID), q, job)
 	})
+	if err != nil {
+		return job, err
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("job.id", job.ID))
+	ctx = logging.With(ctx, "job_id", job.ID)
+	slog.InfoContext(ctx, "Job enqueued", logging.Prompt(job.Prompt))
+	return job, nil
+}
+
+// applyOptionOverrides replaces the webhook's Claude options of a job with the
+// ones the request overrides
+func applyOptionOverrides(params *db.EnqueueJobParams, o *models.OptionOverrides) {
+	if o == nil {
+		return
+	}
+	if o.Model != nil {
+		params.Model = sql.NullString{String: *o.Model, Valid: true}
+	}
+	if o.MaxTurns != nil {
+		params.MaxTurns = sql.NullInt64{Int64: int64(*o.MaxTurns), Valid: true}
+	}
+	if o.AppendSystemPrompt != nil && *o.AppendSystemPrompt != "" {
+		prompt := *o.AppendSystemPrompt
+		if params.AppendSystemPrompt.String != "" {
+			prompt = params.AppendSystemPrompt.String + "\n\n" + prompt
+		}
+		params.AppendSystemPrompt = sql.NullString{String: prompt, Valid: true}
+	}
+	if o.WorkingDir != nil {
+		params.WorkingDir = sql.NullString{String: filepath.Clean(*o.WorkingDir), Valid: true}
+	}
+	if o.Continue != nil {
+		params.EnableContinue = *o.Continue
+	}
+}
+
+// waitTimeout returns how long the caller wants to wait for the job result.
+// The wait query parameter (seconds or a duration such as "2m") overrides the request body.
+func waitTimeout(r *http.Request, req models.WebhookRequest) (time.Duration, error) {
+	wait := time.Duration(req.Wait) * time.Second
+	if v := r.URL.Query().Get("wait"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if d, err := time.ParseDuration(v); err == nil {
+			wait = d
+		} else {
+			return 0, fmt.Errorf("invalid wait: %q", v)
+		}
+	}
 	
+	if wait < 0 {
+		return 0, fmt.Errorf("wait must not be negative")
+	}
+	if wait > maxWebhookWait {
+		wait = maxWebhookWait
+	}
+	return wait, nil
+}
+
+// waitForJob polls the job until it reaches a terminal state and writes its result.
+// If the deadline passes first it responds with 202 and the job ID so the caller can poll.
+func (h *WebhookExecutionHandler) waitForJob(w http.ResponseWriter, r *http.Request, jobID int64, wait time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), wait)
+	defer cancel()
+	
+	ticker := time.NewTicker(webhookWaitPollInterval)
+	defer ticker.Stop()
+	
+	for {
+		job, err := h.queries.GetJobStatus(ctx, jobID)
+		if err == nil && isTerminalJobStatus(job.JobStatus) {
+			response := models.NewWebhookResponse(job.Prompt, job.JobStatus == "completed")
+			response.JobID = job.ID
+			response.Response = job.Response.String
+			response.Error = job.ErrorMessage.String
+			response.SessionID = job.SessionID.String
+			response.Thread = job.Thread.String
+			if job.JobStatus == "cancelled" && response.Error == "" {
+				response.Error = "job cancelled"
+			}
+			if job.ExecutionTimeMs.Valid {
+				response.ExecutionTime = fmt.Sprintf("%.2fs", (time.Duration(job.ExecutionTimeMs.Int64) * time.Millisecond).Seconds())
+			}
+			
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		
+		select {
+		case <-ctx.Done():
+			// The caller went away; nobody is left to answer
+			if r.Context().Err() != nil {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "accepted",
+				"message": "Job did not finish before the wait deadline",
+				"job_id":  jobID,
+			})
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleJobStatus returns the status of a job enqueued through the webhook.
+// Jobs of other webhooks are reported as not found so keys cannot probe them.
+func (h *WebhookExecutionHandler) HandleJobStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	webhookID := vars["uuid"]
+	
+	if _, err := h.queries.GetWebhook(ctx, webhookID); err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	
+	if _, ok := h.authenticate(w, r, webhookID); !ok {
+		return
+	}
+	
+	jobID, err := strconv.ParseInt(vars["id"], 10, 64)
 	if err != nil {
-		http.Error(w, "Failed to enqueue job", http.StatusInternalServerError)
+		http.Error(w, "Invalid job ID", http.StatusBadRequest)
 		return
 	}
 	
-	// Return 200 with job information
-	response := map[string]interface{}{
-		"status":  "accepted",
-		"message": "Webhook execution enqueued",
-		"job_id":  job.ID,
+	job, err := h.queries.GetJobStatus(ctx, jobID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if job.WebhookID != webhookID {
+		http.NotFound(w, r)
+		return
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(newJobStatusResponse(job))
 }
 
+func newJobStatusResponse(job db.JobQueue) *models.JobStatusResponse {
+	response := &models.JobStatusResponse{
+		JobID:       job.ID,
+		Status:      job.JobStatus,
+		CreatedAt:   job.CreatedAt.UTC().Format(time.RFC3339),
+		StartedAt:   formatNullTime(job.StartedAt),
+		CompletedAt: formatNullTime(job.CompletedAt),
+		RetryCount:  job.RetryCount,
+		MaxRetries:  job.MaxRetries,
+		Response:    job.Response.String,
+		Error:       job.ErrorMessage.String,
+		SessionID:   job.SessionID.String,
+		Thread:      job.Thread.String,
+	}
+	if job.JobStatus == "pending" {
+		response.NextAttemptAt = formatNullTime(job.NextAttemptAt)
+	}
+	if job.ExecutionTimeMs.Valid {
+		response.ExecutionTimeMs = &job.ExecutionTimeMs.Int64
+	}
+	return response
+}
+
+// formatNullTime formats a nullable timestamp as RFC 3339 in UTC
+func formatNullTime(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.UTC().Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandleWebhookExecutionResume(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		body   string
+		status int
+		reason string
+	}{
+		{"thread without policy", "", `{"prompt":"p","thread":"issue-42"}`, http.StatusBadRequest, "does not allow resuming"},
+		{"session without resume", `{"continue":true}`, `{"prompt":"p","session_id":"sess-1"}`, http.StatusBadRequest, "does not allow resuming"},
+		{"thread with resume", `{"resume":true}`, `{"prompt":"p","thread":"issue-42"}`, http.StatusOK, ""},
+		{"unknown session with resume", `{"resume":true}`, `{"prompt":"p","session_id":"sess-1"}`, http.StatusBadRequest, "unknown session_id"},
+		{"no session without policy", "", `{"prompt":"p"}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, queries := newTestDB(t)
+			createTestWebhook(t, conn, "resume")
+			if _, err := conn.Exec("UPDATE webhooks SET override_policy = NULLIF(?, '') WHERE id = 'resume'", tt.policy); err != nil {
+				t.Fatal(err)
+			}
+
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/webhooks/resume", strings.NewReader(tt.body)), map[string]string{"uuid": "resume"})
+			rec := httptest.NewRecorder()
+			NewWebhookExecutionHandler(queries).HandleWebhookExecution(rec, req)
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.reason) {
+				t.Errorf("status = %d, body = %q; want %d %q", rec.Code, rec.Body, tt.status, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// logPrompts is set when debug logging is enabled; prompts are redacted otherwise
+var logPrompts bool
+
+// Setup makes slog's default logger, and with it the standard log package, write
+// to w at the given level ("debug", "info", "warn" or "error") in the given
+// format ("json" or "text"). Every record carries the attributes added to its
+// context with With.
+func Setup(w io.Writer, level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+
+	logPrompts = lvl <= slog.LevelDebug
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+type contextKey struct{}
+
+// With returns ctx with attributes, given as alternating keys and values or as
+// slog.Attr, that are added to every record logged with the context
+func With(ctx context.Context, args ...any) context.Context {
+	var r slog.Record
+	r.Add(args...)
+
+	attrs := append([]slog.Attr(nil), attrsFrom(ctx)...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, contextKey{}, attrs)
+}
+
+func attrsFrom(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// Prompt returns the prompt as a log attribute. Prompts may contain secrets or
+// personal data, so only their size is logged unless debug logging is enabled.
+func Prompt(prompt string) slog.Attr {
+	if !logPrompts {
+		return slog.String("prompt", fmt.Sprintf("[redacted, %d bytes]", len(prompt)))
+	}
+	return slog.String("prompt", prompt)
+}
+
+// contextHandler adds the attributes stored by With to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(attrsFrom(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
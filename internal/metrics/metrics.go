@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "claude_pull_worker"
+
+// Outcomes of a job attempt
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeRetrying  = "retrying"
+	OutcomeCancelled = "cancelled"
+)
+
+// Results of a notification or callback delivery attempt
+const (
+	ResultDelivered = "delivered"
+	ResultRetrying  = "retrying"
+	ResultFailed    = "failed"
+)
+
+var (
+	jobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_total",
+		Help:      "Jobs processed by the workers, by webhook and final outcome.",
+	}, []string{"webhook_id", "outcome"})
+
+	jobRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_retries_total",
+		Help:      "Failed job attempts that were scheduled to be retried.",
+	}, []string{"webhook_id"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of job attempts, by webhook and outcome.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"webhook_id", "outcome"})
+
+	// NotificationDeliveries counts attempts at sending queued notifications
+	NotificationDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_deliveries_total",
+		Help:      "Notification delivery attempts, by notifier and result.",
+	}, []string{"notifier", "result"})
+
+	// CallbackDeliveries counts attempts at POSTing job results to callback URLs
+	CallbackDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "callback_deliveries_total",
+		Help:      "Callback delivery attempts, by result.",
+	}, []string{"result"})
+
+	// AuthFailures counts webhook requests rejected for a missing or invalid API key
+	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_failures_total",
+		Help:      "Webhook requests rejected by API key authentication, by webhook and reason.",
+	}, []string{"webhook_id", "reason"})
+
+	workers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers",
+		Help:      "Queue workers, by state (busy or idle).",
+	}, []string{"state"})
+)
+
+// ObserveJob records the outcome and duration of a job attempt
+func ObserveJob(webhookID, outcome string, duration time.Duration) {
+	jobDuration.WithLabelValues(webhookID, outcome).Observe(duration.Seconds())
+	if outcome == OutcomeRetrying {
+		jobRetriesTotal.WithLabelValues(webhookID).Inc()
+		return
+	}
+	jobsTotal.WithLabelValues(webhookID, outcome).Inc()
+}
+
+// WorkerStarted counts a worker that started waiting for jobs
+func WorkerStarted() {
+	workers.WithLabelValues("idle").Inc()
+}
+
+// WorkerStopped removes a stopped idle worker
+func WorkerStopped() {
+	workers.WithLabelValues("idle").Dec()
+}
+
+// WorkerBusy marks an idle worker as processing a job
+func WorkerBusy() {
+	workers.WithLabelValues("idle").Dec()
+	workers.WithLabelValues("busy").Inc()
+}
+
+// WorkerIdle marks a busy worker as waiting for jobs again
+func WorkerIdle() {
+	workers.WithLabelValues("busy").Dec()
+	workers.WithLabelValues("idle").Inc()
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
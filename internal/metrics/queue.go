@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+)
+
+// jobStatuses are reported even when no job has them, so queries see zeros
+var jobStatuses = []string{"pending", "processing", "completed", "failed", "cancelled"}
+
+// queueCollector reads the number of jobs in each status from the database on every scrape
+type queueCollector struct {
+	queries *db.Queries
+	jobs    *prometheus.Desc
+}
+
+// RegisterQueue exposes the job queue depth by status
+func RegisterQueue(queries *db.Queries) {
+	prometheus.MustRegister(&queueCollector{
+		queries: queries,
+		jobs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "queue_jobs"),
+			"Jobs in the queue, by status.",
+			[]string{"status"}, nil,
+		),
+	})
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.jobs
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := c.queries.CountJobsByStatus(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to count jobs for metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.jobs, err)
+		return
+	}
+
+	counts := make(map[string]int64, len(jobStatuses))
+	for _, status := range jobStatuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.JobStatus] = row.Count
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.jobs, prometheus.GaugeValue, float64(count), status)
+	}
+}
@@ -4,15 +4,90 @@ import "time"
 
 type WebhookRequest struct {
 	Prompt string `json:"prompt"`
+	// Variables render the webhook's prompt template into the prompt; a request
+	// sends either a prompt or variables
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	// Wait is the number of seconds to wait for the job result before
+	// responding; 0 responds as soon as the job is enqueued
+	Wait int `json:"wait,omitempty"`
+	// CallbackURL receives the result as a POST once the job finishes.
+	// With CallbackSecret set the body is signed with HMAC-SHA256.
+	CallbackURL    string `json:"callback_url,omitempty"`
+	CallbackSecret string `json:"callback_secret,omitempty"`
+	// Options override the webhook's Claude options within its override policy
+	Options *OptionOverrides `json:"options,omitempty"`
+	// SessionID resumes the Claude session of an earlier job of the webhook
+	SessionID string `json:"session_id,omitempty"`
+	// Thread names a conversation; each job resumes the session the previous
+	// job of the thread ended in. A request sends either a session_id or a thread.
+	Thread string `json:"thread,omitempty"`
 }
 
+// OptionOverrides are the Claude options a request can change. Unset fields
+// keep the webhook's configuration.
+type OptionOverrides struct {
+	Model    *string `json:"model,omitempty"`
+	MaxTurns *int    `json:"max_turns,omitempty"`
+	// AppendSystemPrompt is appended after the webhook's own append_system_prompt
+	AppendSystemPrompt *string `json:"append_system_prompt,omitempty"`
+	WorkingDir         *string `json:"working_dir,omitempty"`
+	Continue           *bool   `json:"continue,omitempty"`
+}
+
+// Job lifecycle events that notifications report
+const (
+	EventEnqueued  = "enqueued"
+	EventStarted   = "started"
+	EventSucceeded = "succeeded"
+	EventFailed    = "failed"
+	EventRetrying  = "retrying"
+	EventCancelled = "cancelled"
+)
+
 type WebhookResponse struct {
+	JobID int64 `json:"job_id,omitempty"`
+	// Event is the lifecycle event a notification reports; empty for webhook replies
+	Event         string `json:"event,omitempty"`
 	Success       bool   `json:"success"`
 	Timestamp     string `json:"timestamp"`
 	Prompt        string `json:"prompt"`
 	Response      string `json:"response"`
 	ExecutionTime string `json:"execution_time"`
 	Error         string `json:"error,omitempty"`
+	// SessionID is the Claude session the job ended in, to resume it later
+	SessionID string `json:"session_id,omitempty"`
+	Thread    string `json:"thread,omitempty"`
+}
+
+// StatusLabel describes the reported event for humans, falling back to the
+// outcome when no event is set
+func (r *WebhookResponse) StatusLabel() string {
+	switch r.Event {
+	case EventEnqueued:
+		return "Queued"
+	case EventStarted:
+		return "Started"
+	case EventRetrying:
+		return "Failed (retrying)"
+	case EventCancelled:
+		return "Cancelled"
+	}
+	if r.Success {
+		return "Success"
+	}
+	return "Failed"
+}
+
+// JobMetadata describes the job a notification reports on, for notifiers
+// that render their payload from templates
+type JobMetadata struct {
+	ID          int64  `json:"id"`
+	WebhookID   string `json:"webhook_id"`
+	WebhookName string `json:"webhook_name"`
+	Status      string `json:"status"`
+	RetryCount  int64  `json:"retry_count"`
+	MaxRetries  int64  `json:"max_retries"`
+	CreatedAt   string `json:"created_at"`
 }
 
 func NewWebhookResponse(prompt string, success bool) *WebhookResponse {
@@ -21,4 +96,21 @@ func NewWebhookResponse(prompt string, success bool) *WebhookResponse {
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Prompt:    prompt,
 	}
-}
\ No newline at end of file
+}
+
+// JobStatusResponse describes a queued job to the webhook caller that enqueued it
+type JobStatusResponse struct {
+	JobID           int64   `json:"job_id"`
+	Status          string  `json:"status"`
+	CreatedAt       string  `json:"created_at"`
+	StartedAt       *string `json:"started_at,omitempty"`
+	CompletedAt     *string `json:"completed_at,omitempty"`
+	NextAttemptAt   *string `json:"next_attempt_at,omitempty"`
+	RetryCount      int64   `json:"retry_count"`
+	MaxRetries      int64   `json:"max_retries"`
+	ExecutionTimeMs *int64  `json:"execution_time_ms,omitempty"`
+	Response        string  `json:"response,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	SessionID       string  `json:"session_id,omitempty"`
+	Thread          string  `json:"thread,omitempty"`
+}
@@ -3,34 +3,75 @@ package discord
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
 )
 
 const (
 	ColorSuccess = 0x00ff00 // Green
 	ColorError   = 0xff0000 // Red
+	ColorInfo    = 0x3b82f6 // Blue, for jobs that have not finished yet
 	MaxFieldLen  = 1024
 	MaxDescLen   = 2048
+	// Timeout bounds a single webhook request
+	Timeout = 30 * time.Second
+
+	// MaxPages bounds how many embeds a split response is sent as
+	MaxPages = 10
+	// MaxEmbedsPerMessage and MaxMessageLen are Discord's limits on the number
+	// of embeds in a message and the total length of their text
+	MaxEmbedsPerMessage = 10
+	MaxMessageLen       = 6000
+	// MaxPreviewLen is the length of the response preview sent with an attachment
+	MaxPreviewLen = 1000
+	// AttachmentName is the file name of the uploaded full response
+	AttachmentName = "response.md"
+
+	// maxInlineRetryAfter is the longest rate limit waited out between the
+	// messages of a split response instead of failing the notification
+	maxInlineRetryAfter = 10 * time.Second
 )
 
 type Client struct {
-	webhookURL string
+	config     Config
+	httpClient *http.Client
 }
 
-func NewClient(webhookURL string) *Client {
+func NewClient(config Config) *Client {
 	return &Client{
-		webhookURL: webhookURL,
+		config:     config,
+		httpClient: &http.Client{Timeout: Timeout},
 	}
 }
 
 func (c *Client) SendNotification(resp *models.WebhookResponse) error {
+	_, err := c.SendNotificationFrom(resp, 0)
+	return err
+}
+
+// SendNotificationFrom sends the notification, skipping the first sent messages
+// of a split response, which an earlier attempt posted. It returns how many
+// messages have been posted in total.
+func (c *Client) SendNotificationFrom(resp *models.WebhookResponse, sent int) (int, error) {
 	color := ColorSuccess
+	text := resp.Response
+	pageTitle := "Response"
 	if !resp.Success {
 		color = ColorError
+		text = fmt.Sprintf("Error: %s", resp.Error)
+		pageTitle = "Error"
+	}
+	if resp.Event == models.EventEnqueued || resp.Event == models.EventStarted {
+		color = ColorInfo
 	}
 
 	embed := Embed{
@@ -40,7 +81,7 @@ func (c *Client) SendNotification(resp *models.WebhookResponse) error {
 		Fields: []Field{
 			{
 				Name:   "Prompt",
-				Value:  truncate(resp.Prompt, MaxFieldLen),
+				Value:  notifier.Truncate(resp.Prompt, MaxFieldLen, "..."),
 				Inline: false,
 			},
 			{
@@ -50,50 +91,201 @@ func (c *Client) SendNotification(resp *models.WebhookResponse) error {
 			},
 			{
 				Name:   "Status",
-				Value:  fmt.Sprintf("%v", resp.Success),
+				Value:  resp.StatusLabel(),
 				Inline: true,
 			},
 		},
 	}
 
-	if resp.Success {
-		embed.Description = truncate(resp.Response, MaxDescLen)
-	} else {
-		embed.Description = fmt.Sprintf("Error: %s", truncate(resp.Error, MaxDescLen))
+	if utf8.RuneCountInString(text) <= MaxDescLen {
+		embed.Description = text
+		return sentOne(c.post(Webhook{Embeds: []Embed{embed}}))
+	}
+
+	switch c.config.LongResponse {
+	case LongResponseSplit:
+		pages := splitText(text, MaxDescLen)
+		if len(pages) > MaxPages {
+			pages = pages[:MaxPages]
+			pages[MaxPages-1] = notifier.Truncate(pages[MaxPages-1], MaxDescLen-40, "...") + "\n\n(response too long, truncated)"
+		}
+
+		embed.Description = pages[0]
+		embeds := []Embed{embed}
+		for i, page := range pages[1:] {
+			embeds = append(embeds, Embed{
+				Title:       fmt.Sprintf("%s (%d/%d)", pageTitle, i+2, len(pages)),
+				Description: page,
+				Color:       color,
+			})
+		}
+		return c.postAll(packEmbeds(embeds), sent)
+
+	case LongResponseAttachment:
+		embed.Description = notifier.Truncate(text, MaxPreviewLen, "...") + fmt.Sprintf("\n\nThe full %s is attached as %s.", strings.ToLower(pageTitle), AttachmentName)
+		return sentOne(c.postWithFile(Webhook{Embeds: []Embed{embed}}, AttachmentName, []byte(text)))
+
+	default:
+		embed.Description = notifier.Truncate(text, MaxDescLen, "...")
+		return sentOne(c.post(Webhook{Embeds: []Embed{embed}}))
+	}
+}
+
+// sentOne reports the single message of a notification as posted unless err is set
+func sentOne(err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (c *Client) Name() string {
+	return "discord"
+}
+
+// postAll sends the messages of a split response in order, starting after the
+// first sent ones, and returns how many have been posted. Short rate limits
+// between messages are waited out instead of failing the attempt.
+func (c *Client) postAll(messages []Webhook, sent int) (int, error) {
+	for i := sent; i < len(messages); i++ {
+		err := c.post(messages[i])
+		for attempt := 0; err != nil && i > 0 && attempt < 3; attempt++ {
+			var rateLimited *notifier.RetryAfterError
+			if !errors.As(err, &rateLimited) || rateLimited.RetryAfter > maxInlineRetryAfter {
+				break
+			}
+			time.Sleep(rateLimited.RetryAfter)
+			err = c.post(messages[i])
+		}
+		if err != nil {
+			return i, fmt.Errorf("failed to send message %d/%d: %w", i+1, len(messages), err)
+		}
 	}
+	return len(messages), nil
+}
 
-	webhook := Webhook{
-		Embeds: []Embed{embed},
+// post sends a message as JSON
+func (c *Client) post(webhook Webhook) error {
+	payload, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Discord webhook: %w", err)
 	}
 
+	return c.send("application/json", bytes.NewBuffer(payload))
+}
+
+// postWithFile sends a message with a file attachment as multipart/form-data
+func (c *Client) postWithFile(webhook Webhook, filename string, content []byte) error {
 	payload, err := json.Marshal(webhook)
 	if err != nil {
-		log.Printf("Error marshaling Discord webhook: %v", err)
 		return fmt.Errorf("failed to marshal Discord webhook: %w", err)
 	}
 
-	httpResp, err := http.Post(c.webhookURL, "application/json", bytes.NewBuffer(payload))
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("payload_json", string(payload)); err != nil {
+		return fmt.Errorf("failed to build Discord attachment: %w", err)
+	}
+	file, err := w.CreateFormFile("files[0]", filename)
+	if err != nil {
+		return fmt.Errorf("failed to build Discord attachment: %w", err)
+	}
+	file.Write(content)
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to build Discord attachment: %w", err)
+	}
+
+	return c.send(w.FormDataContentType(), &body)
+}
+
+func (c *Client) send(contentType string, body io.Reader) error {
+	httpResp, err := c.httpClient.Post(c.config.WebhookURL, contentType, body)
 	if err != nil {
-		log.Printf("Error sending Discord notification: %v", err)
 		return fmt.Errorf("failed to send Discord notification: %w", err)
 	}
 	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode != http.StatusNoContent {
-		log.Printf("Discord webhook returned status: %d", httpResp.StatusCode)
-		return fmt.Errorf("Discord webhook returned unexpected status: %d", httpResp.StatusCode)
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := notifier.RetryAfterHeader(httpResp.Header)
+		var rateLimit RateLimitResponse
+		if err := json.NewDecoder(httpResp.Body).Decode(&rateLimit); err == nil && rateLimit.RetryAfter > 0 {
+			retryAfter = time.Duration(rateLimit.RetryAfter * float64(time.Second))
+		}
+		return &notifier.RetryAfterError{
+			Err:        fmt.Errorf("Discord webhook rate limited"),
+			RetryAfter: retryAfter,
+		}
+	}
+
+	// Discord answers 204 No Content, or 200 with the message when ?wait=true is set
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return notifier.StatusError(httpResp.StatusCode, fmt.Errorf("Discord webhook returned unexpected status: %d", httpResp.StatusCode))
 	}
 
 	return nil
 }
 
-func (c *Client) Name() string {
-	return "discord"
+// packEmbeds groups embeds into as few messages as Discord's per-message limits allow
+func packEmbeds(embeds []Embed) []Webhook {
+	var messages []Webhook
+	var current Webhook
+	length := 0
+	for _, embed := range embeds {
+		n := embedLen(embed)
+		if len(current.Embeds) > 0 && (len(current.Embeds) == MaxEmbedsPerMessage || length+n > MaxMessageLen) {
+			messages = append(messages, current)
+			current = Webhook{}
+			length = 0
+		}
+		current.Embeds = append(current.Embeds, embed)
+		length += n
+	}
+	if len(current.Embeds) > 0 {
+		messages = append(messages, current)
+	}
+	return messages
+}
+
+// embedLen counts the text of an embed the way Discord does for MaxMessageLen
+func embedLen(embed Embed) int {
+	n := textLen(embed.Title) + textLen(embed.Description)
+	for _, field := range embed.Fields {
+		n += textLen(field.Name) + textLen(field.Value)
+	}
+	return n
+}
+
+// textLen returns the length of s in UTF-16 code units, which is how Discord measures text
+func textLen(s string) int {
+	n := 0
+	for _, r := range s {
+		if r > 0xFFFF {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// splitText cuts s into pages of at most size runes, preferring to break after
+// a newline in the second half of a page so Markdown blocks stay intact
+func splitText(s string, size int) []string {
+	runes := []rune(s)
+	var pages []string
+	for len(runes) > size {
+		cut := size
+		for i := size - 1; i >= size/2; i-- {
+			if runes[i] == '\n' {
+				cut = i + 1
+				break
+			}
+		}
+		pages = append(pages, string(runes[:cut]))
+		runes = runes[cut:]
+	}
+	if len(runes) > 0 {
+		pages = append(pages, string(runes))
 	}
-	return s[:maxLen-3] + "..."
-}
\ No newline at end of file
+	return pages
+}
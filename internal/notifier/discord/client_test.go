@@ -0,0 +1,62 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+func TestSendNotificationFromResumesSplitResponse(t *testing.T) {
+	var mu sync.Mutex
+	var posted []string
+	failNext := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message Webhook
+		json.NewDecoder(r.Body).Decode(&message)
+		mu.Lock()
+		defer mu.Unlock()
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		posted = append(posted, message.Embeds[0].Description[:1])
+		failNext = len(posted) == 1
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Five pages of MaxDescLen each, packed two or three to a message
+	var text strings.Builder
+	for _, page := range []string{"a", "b", "c", "d", "e"} {
+		text.WriteString(strings.Repeat(page, MaxDescLen))
+	}
+	resp := models.NewWebhookResponse("prompt", true)
+	resp.Response = text.String()
+	client := NewClient(Config{WebhookURL: server.URL, LongResponse: LongResponseSplit})
+
+	sent, err := client.SendNotificationFrom(resp, 0)
+	if err == nil {
+		t.Fatal("the second message was rejected, want an error")
+	}
+	if sent != 1 {
+		t.Fatalf("sent = %d, want 1", sent)
+	}
+
+	// The retry posts the rest without the message that already went out
+	total, err := client.SendNotificationFrom(resp, sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(posted) {
+		t.Errorf("total = %d, want %d", total, len(posted))
+	}
+	if first := strings.Count(strings.Join(posted, ""), "a"); first != 1 {
+		t.Errorf("the first message was posted %d times, want once (posted %v)", first, posted)
+	}
+}
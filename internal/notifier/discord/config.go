@@ -0,0 +1,29 @@
+package discord
+
+import "fmt"
+
+// How responses longer than MaxDescLen are sent
+const (
+	// LongResponseTruncate cuts the response off at MaxDescLen (the default)
+	LongResponseTruncate = "truncate"
+	// LongResponseSplit sends the response as several embeds, across several messages if needed
+	LongResponseSplit = "split"
+	// LongResponseAttachment sends a preview and uploads the full response as AttachmentName
+	LongResponseAttachment = "attachment"
+)
+
+// Config is the "discord" entry of a notification config
+type Config struct {
+	WebhookURL   string `json:"webhook_url"`
+	LongResponse string `json:"long_response,omitempty"`
+}
+
+// Validate checks the long response mode
+func (c Config) Validate() error {
+	switch c.LongResponse {
+	case "", LongResponseTruncate, LongResponseSplit, LongResponseAttachment:
+		return nil
+	default:
+		return fmt.Errorf("unknown long_response mode %q", c.LongResponse)
+	}
+}
@@ -6,10 +6,10 @@ type Webhook struct {
 }
 
 type Embed struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
 	Color       int     `json:"color"`
-	Timestamp   string  `json:"timestamp"`
+	Timestamp   string  `json:"timestamp,omitempty"`
 	Fields      []Field `json:"fields,omitempty"`
 }
 
@@ -17,4 +17,11 @@ type Field struct {
 	Name   string `json:"name"`
 	Value  string `json:"value"`
 	Inline bool   `json:"inline"`
+}
+
+// RateLimitResponse is the body Discord sends with a 429 response
+type RateLimitResponse struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
 }
\ No newline at end of file
@@ -0,0 +1,252 @@
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// MaxInlineLen is the longest response included in the body in full; longer
+	// responses are shortened in the body and attached as a Markdown file
+	MaxInlineLen = 4000
+	// MaxSubjectPromptLen bounds the part of the prompt quoted in the subject
+	MaxSubjectPromptLen = 60
+	// AttachmentName is the file name of the attached full response
+	AttachmentName = "response.md"
+	// Timeout bounds the whole SMTP conversation
+	Timeout = 30 * time.Second
+)
+
+var htmlBody = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #111827;">
+<h2 style="color: {{if .Success}}#16a34a{{else}}#dc2626{{end}};">Claude Code Execution Result</h2>
+<table cellpadding="4" style="border-collapse: collapse;">
+<tr><th align="left">Status</th><td>{{.Status}}</td></tr>
+<tr><th align="left">Execution Time</th><td>{{.ExecutionTime}}</td></tr>
+<tr><th align="left">Timestamp</th><td>{{.Timestamp}}</td></tr>
+</table>
+<h3>Prompt</h3>
+<pre style="white-space: pre-wrap; background: #f3f4f6; padding: 8px;">{{.Prompt}}</pre>
+<h3>{{if .Success}}Response{{else}}Error{{end}}</h3>
+<pre style="white-space: pre-wrap; background: #f3f4f6; padding: 8px;">{{.Result}}</pre>
+{{if .Attached}}<p><em>The full response is attached as {{.AttachmentName}}.</em></p>{{end}}
+</body>
+</html>
+`))
+
+type Client struct {
+	config Config
+}
+
+func NewClient(config Config) *Client {
+	return &Client{
+		config: config,
+	}
+}
+
+func (c *Client) SendNotification(resp *models.WebhookResponse) error {
+	if err := c.config.Validate(); err != nil {
+		return fmt.Errorf("invalid email config: %w", err)
+	}
+
+	message, err := c.buildMessage(resp)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	if err := c.send(message); err != nil {
+		return fmt.Errorf("failed to send email notification: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) Name() string {
+	return "email"
+}
+
+// send delivers message to all recipients over a single SMTP session
+func (c *Client) send(message []byte) error {
+	conn, err := net.DialTimeout("tcp", c.config.addr(), Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(Timeout))
+
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.config.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server %s does not support STARTTLS", c.config.Host)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: c.config.Host}); err != nil {
+			return err
+		}
+	}
+
+	if c.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.config.From); err != nil {
+		return err
+	}
+	for _, to := range c.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders resp as a multipart/mixed message with plaintext and HTML
+// alternatives, attaching the full response when it is too long to inline
+func (c *Client) buildMessage(resp *models.WebhookResponse) ([]byte, error) {
+	status := resp.StatusLabel()
+	result := resp.Response
+	if !resp.Success {
+		result = resp.Error
+	}
+
+	attached := len([]rune(result)) > MaxInlineLen
+	inline := result
+	if attached {
+		inline = notifier.Truncate(result, MaxInlineLen, "...")
+	}
+
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+	subject := fmt.Sprintf("[Claude Code] %s: %s", status, notifier.Truncate(resp.Prompt, MaxSubjectPromptLen, "..."))
+	fmt.Fprintf(&buf, "From: %s\r\n", c.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(c.config.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	// Plaintext and HTML bodies
+	var alternative bytes.Buffer
+	alt := multipart.NewWriter(&alternative)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Claude Code Execution Result\n\n")
+	fmt.Fprintf(&text, "Status: %s\nExecution Time: %s\nTimestamp: %s\n\n", status, resp.ExecutionTime, resp.Timestamp)
+	fmt.Fprintf(&text, "Prompt:\n%s\n\n", resp.Prompt)
+	if resp.Success {
+		fmt.Fprintf(&text, "Response:\n%s\n", inline)
+	} else {
+		fmt.Fprintf(&text, "Error:\n%s\n", inline)
+	}
+	if attached {
+		fmt.Fprintf(&text, "\nThe full response is attached as %s.\n", AttachmentName)
+	}
+	if err := writeQuotedPrintable(alt, "text/plain; charset=utf-8", []byte(text.String())); err != nil {
+		return nil, err
+	}
+
+	var html bytes.Buffer
+	if err := htmlBody.Execute(&html, map[string]interface{}{
+		"Success":        resp.Success,
+		"Status":         status,
+		"ExecutionTime":  resp.ExecutionTime,
+		"Timestamp":      resp.Timestamp,
+		"Prompt":         resp.Prompt,
+		"Result":         inline,
+		"Attached":       attached,
+		"AttachmentName": AttachmentName,
+	}); err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(alt, "text/html; charset=utf-8", html.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := alt.Close(); err != nil {
+		return nil, err
+	}
+
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alt.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	part.Write(alternative.Bytes())
+
+	// Full response as a Markdown attachment
+	if attached {
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"text/markdown; charset=utf-8"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": AttachmentName})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, []byte(result))
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w *multipart.Writer, contentType string, body []byte) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write(body); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 writes data base64-encoded in lines of 76 characters as required by RFC 2045
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
@@ -0,0 +1,271 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// fakeSMTPServer accepts SMTP sessions on a local port and records what it receives
+type fakeSMTPServer struct {
+	listener net.Listener
+	done     chan struct{}
+
+	from       string
+	recipients []string
+	data       []byte
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) config() Config {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return Config{Host: host, Port: p, From: "worker@example.com", To: []string{"alice@example.com"}}
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			// STARTTLS is never offered
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = address(line[len("MAIL FROM:"):])
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.recipients = append(s.recipients, address(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data bytes.Buffer
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data = data.Bytes()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// address returns the path in angle brackets of a MAIL or RCPT argument, dropping parameters
+func address(arg string) string {
+	start, end := strings.Index(arg, "<"), strings.Index(arg, ">")
+	if start < 0 || end < start {
+		return arg
+	}
+	return arg[start+1 : end]
+}
+
+// wait returns once the session has ended so its recorded fields can be read
+func (s *fakeSMTPServer) wait() {
+	<-s.done
+}
+
+// parsedMessage is a received message split into its parts, bodies decoded
+type parsedMessage struct {
+	header      mail.Header
+	text        string
+	html        string
+	attachments map[string][]byte
+}
+
+func parseMessage(t *testing.T, data []byte) parsedMessage {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	parsed := parsedMessage{header: msg.Header, attachments: map[string][]byte{}}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+	mixed := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mixed.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		mediaType, params, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch {
+		case mediaType == "multipart/alternative":
+			alt := multipart.NewReader(part, params["boundary"])
+			for {
+				p, err := alt.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				// NextPart decodes quoted-printable bodies, whose line breaks are CRLF
+				body, _ := io.ReadAll(p)
+				text := strings.ReplaceAll(string(body), "\r\n", "\n")
+				switch p.Header.Get("Content-Type") {
+				case "text/plain; charset=utf-8":
+					parsed.text = text
+				case "text/html; charset=utf-8":
+					parsed.html = text
+				}
+			}
+		case part.FileName() != "":
+			if enc := part.Header.Get("Content-Transfer-Encoding"); enc != "base64" {
+				t.Errorf("attachment encoding = %q, want base64", enc)
+			}
+			body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+			if err != nil {
+				t.Fatalf("invalid base64 attachment: %v", err)
+			}
+			parsed.attachments[part.FileName()] = body
+		}
+	}
+	return parsed
+}
+
+func TestSendNotification(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	client := NewClient(server.config())
+
+	err := client.SendNotification(&models.WebhookResponse{
+		Success:       true,
+		Prompt:        "Summarize <the> logs",
+		Response:      "All good ✓",
+		ExecutionTime: "1.5s",
+		Timestamp:     "2025-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	server.wait()
+
+	if server.from != "worker@example.com" {
+		t.Errorf("MAIL FROM = %q", server.from)
+	}
+	msg := parseMessage(t, server.data)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.header.Get("Subject"))
+	if subject != "[Claude Code] Success: Summarize <the> logs" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if !strings.Contains(msg.text, "Status: Success") || !strings.Contains(msg.text, "Response:\nAll good ✓") {
+		t.Errorf("plaintext body missing result:\n%s", msg.text)
+	}
+	if !strings.Contains(msg.html, "Summarize &lt;the&gt; logs") || !strings.Contains(msg.html, "All good ✓") {
+		t.Errorf("HTML body missing escaped prompt or result:\n%s", msg.html)
+	}
+	if len(msg.attachments) != 0 {
+		t.Errorf("unexpected attachments: %v", msg.attachments)
+	}
+}
+
+func TestSendNotificationAttachesLongResponse(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	client := NewClient(server.config())
+
+	response := strings.Repeat("é", MaxInlineLen+1)
+	if err := client.SendNotification(&models.WebhookResponse{Success: true, Prompt: "long", Response: response}); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	server.wait()
+
+	msg := parseMessage(t, server.data)
+	if got := string(msg.attachments[AttachmentName]); got != response {
+		t.Errorf("attachment has %d runes, want the full response of %d", len([]rune(got)), len([]rune(response)))
+	}
+	if strings.Contains(msg.text, response) {
+		t.Error("plaintext body contains the full response")
+	}
+	if !strings.Contains(msg.text, "attached as "+AttachmentName) || !strings.Contains(msg.html, "attached as "+AttachmentName) {
+		t.Error("bodies do not mention the attachment")
+	}
+}
+
+func TestSendNotificationMultipleRecipients(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config()
+	config.To = []string{"alice@example.com", "bob@example.com"}
+
+	if err := NewClient(config).SendNotification(&models.WebhookResponse{Success: false, Prompt: "p", Error: "boom"}); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+	server.wait()
+
+	if strings.Join(server.recipients, ",") != "alice@example.com,bob@example.com" {
+		t.Errorf("RCPT TO = %v", server.recipients)
+	}
+	msg := parseMessage(t, server.data)
+	if to := msg.header.Get("To"); to != "alice@example.com, bob@example.com" {
+		t.Errorf("To = %q", to)
+	}
+	if !strings.Contains(msg.text, "Error:\nboom") {
+		t.Errorf("plaintext body missing error:\n%s", msg.text)
+	}
+}
+
+func TestSendNotificationRequiresSTARTTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config()
+	config.StartTLS = true
+
+	err := NewClient(config).SendNotification(&models.WebhookResponse{Success: true, Prompt: "p"})
+	if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Fatalf("SendNotification error = %v, want STARTTLS unsupported", err)
+	}
+	server.wait()
+	if server.data != nil {
+		t.Error("message was sent without STARTTLS")
+	}
+}
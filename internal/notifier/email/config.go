@@ -0,0 +1,71 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort is the SMTP submission port used when none is configured
+const DefaultPort = 587
+
+// Config is the "email" entry of a notification config
+type Config struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	StartTLS bool     `json:"starttls"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// ParseConfig decodes the "email" entry of a decoded notification config
+func ParseConfig(raw map[string]interface{}) (Config, error) {
+	var config Config
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(b, &config)
+	return config, err
+}
+
+// Validate reports the first setting that prevents a notification from being sent
+func (c Config) Validate() error {
+	if c.Host == "" {
+		return errors.New("host is required")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+	if c.From == "" {
+		return errors.New("from is required")
+	}
+	if len(c.To) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	return nil
+}
+
+// ParseRecipients splits a comma- or newline-separated recipient list
+func ParseRecipients(s string) []string {
+	var recipients []string
+	for _, r := range strings.FieldsFunc(s, func(c rune) bool { return c == ',' || c == '\n' }) {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients
+}
+
+func (c Config) addr() string {
+	port := c.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
@@ -0,0 +1,102 @@
+package generichttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+// Timeout bounds a single notification request
+const Timeout = 30 * time.Second
+
+// funcs are available in body templates
+var funcs = template.FuncMap{
+	// json encodes a value as JSON, e.g. a string as a quoted and escaped literal
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// truncate shortens a string to at most n runes
+	"truncate": func(n int, s string) string {
+		if n <= 3 {
+			return notifier.Truncate(s, n, "")
+		}
+		return notifier.Truncate(s, n, "...")
+	},
+}
+
+// TemplateData is what body templates are rendered with. The webhook
+// response fields are available directly, e.g. {{.Prompt}}, and the job
+// under .Job, e.g. {{.Job.WebhookName}}.
+type TemplateData struct {
+	*models.WebhookResponse
+	Job models.JobMetadata
+}
+
+type Client struct {
+	config     Config
+	job        models.JobMetadata
+	httpClient *http.Client
+}
+
+func NewClient(config Config, job models.JobMetadata) *Client {
+	return &Client{
+		config:     config,
+		job:        job,
+		httpClient: &http.Client{Timeout: Timeout},
+	}
+}
+
+func (c *Client) SendNotification(resp *models.WebhookResponse) error {
+	tmpl, err := c.config.template()
+	if err != nil {
+		return fmt.Errorf("failed to parse body template: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, TemplateData{WebhookResponse: resp, Job: c.job}); err != nil {
+		return fmt.Errorf("failed to render body template: %w", err)
+	}
+
+	req, err := http.NewRequest(c.config.method(), c.config.URL, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create generic HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "claude-code-pull-worker")
+	for name, value := range c.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send generic HTTP notification: %w", err)
+	}
+	defer httpResp.Body.Close()
+	io.Copy(io.Discard, httpResp.Body)
+
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := notifier.RetryAfterHeader(httpResp.Header)
+		return &notifier.RetryAfterError{
+			Err:        fmt.Errorf("generic HTTP notification rate limited"),
+			RetryAfter: retryAfter,
+		}
+	}
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return notifier.StatusError(httpResp.StatusCode, fmt.Errorf("generic HTTP notification returned unexpected status: %d", httpResp.StatusCode))
+	}
+
+	return nil
+}
+
+func (c *Client) Name() string {
+	return "generic_http"
+}
@@ -0,0 +1,116 @@
+package generichttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// DefaultBody is used when no body template is configured: the webhook
+// response as JSON with the job metadata under "job"
+const DefaultBody = `{"job_id": {{.JobID}}, "success": {{.Success}}, "timestamp": {{json .Timestamp}}, "prompt": {{json .Prompt}}, "response": {{json .Response}}, "execution_time": {{json .ExecutionTime}}, "error": {{json .Error}}, "job": {{json .Job}}}`
+
+// Config is the "generic_http" entry of a notification config
+type Config struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is a text/template rendered with TemplateData
+	Body string `json:"body,omitempty"`
+}
+
+// ParseConfig decodes the "generic_http" entry of a decoded notification config
+func ParseConfig(raw map[string]interface{}) (Config, error) {
+	var config Config
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(b, &config)
+	return config, err
+}
+
+// Validate checks the target URL, method and body template
+func (c Config) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	switch c.method() {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("unsupported method %q", c.Method)
+	}
+	for name := range c.Headers {
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	tmpl, err := c.template()
+	if err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+	// Render once with empty data so references to unknown fields are caught on save
+	if err := tmpl.Execute(io.Discard, TemplateData{WebhookResponse: &models.WebhookResponse{}}); err != nil {
+		return fmt.Errorf("invalid body template: %w", err)
+	}
+	return nil
+}
+
+func (c Config) method() string {
+	if c.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(c.Method)
+}
+
+func (c Config) template() (*template.Template, error) {
+	body := c.Body
+	if body == "" {
+		body = DefaultBody
+	}
+	return template.New("body").Funcs(funcs).Option("missingkey=error").Parse(body)
+}
+
+// ParseHeaders reads one "Name: value" header per line
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// FormatHeaders is the inverse of ParseHeaders
+func FormatHeaders(headers map[string]string) string {
+	var b strings.Builder
+	for _, name := range sortedKeys(headers) {
+		fmt.Fprintf(&b, "%s: %s\n", name, headers[name])
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
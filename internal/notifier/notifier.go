@@ -8,6 +8,13 @@ type Notifier interface {
 	Name() string
 }
 
+// PartialSender is implemented by notifiers that post a notification as several
+// messages. SendNotificationFrom skips the first sent messages, which an earlier
+// attempt posted, and returns how many have been posted in total, also on failure.
+type PartialSender interface {
+	SendNotificationFrom(response *models.WebhookResponse, sent int) (int, error)
+}
+
 // MultiNotifier allows sending notifications to multiple services
 type MultiNotifier struct {
 	notifiers []Notifier
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryAfterError is returned when the service rate limited the notification
+// and asked to wait RetryAfter before trying again
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterHeader parses a Retry-After header given in seconds, returning 0 if it is absent or invalid
+func RetryAfterHeader(h http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(h.Get("Retry-After"), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// PermanentError marks a failed notification that would fail the same way if
+// sent again, such as one the service rejected as invalid
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so that IsRetryable reports false for it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// StatusError marks err, returned for an unexpected HTTP status, as permanent when
+// the status is a client error. Only 408 Request Timeout and 429 Too Many Requests
+// may succeed when sent again.
+func StatusError(status int, err error) error {
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
+
+// IsRetryable reports whether a failed notification is worth sending again
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var permanent *PermanentError
+	return !errors.As(err, &permanent)
+}
@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestStatusErrorRetryable(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, false},
+		{http.StatusGone, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		err := StatusError(tt.status, fmt.Errorf("unexpected status: %d", tt.status))
+		if got := IsRetryable(err); got != tt.retryable {
+			t.Errorf("IsRetryable(StatusError(%d)) = %v, want %v", tt.status, got, tt.retryable)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	if IsRetryable(nil) {
+		t.Error("IsRetryable(nil) = true, want false")
+	}
+	if !IsRetryable(errors.New("connection refused")) {
+		t.Error("a plain error is not retryable, want retryable")
+	}
+	if !IsRetryable(&RetryAfterError{Err: errors.New("rate limited")}) {
+		t.Error("a rate limit is not retryable, want retryable")
+	}
+	if IsRetryable(fmt.Errorf("send: %w", Permanent(errors.New("bad request")))) {
+		t.Error("a wrapped permanent error is retryable, want not retryable")
+	}
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// Events lists every job event a notifier can subscribe to
+var Events = []string{
+	models.EventEnqueued,
+	models.EventStarted,
+	models.EventSucceeded,
+	models.EventFailed,
+	models.EventRetrying,
+	models.EventCancelled,
+}
+
+// DefaultEvents are sent to notifiers whose rules do not list any events
+var DefaultEvents = []string{
+	models.EventSucceeded,
+	models.EventFailed,
+	models.EventRetrying,
+	models.EventCancelled,
+}
+
+// Rules decide which job events a notifier receives. They are stored next to
+// the notifier's settings in its notification_config entry.
+type Rules struct {
+	// Events to send; empty means DefaultEvents
+	Events []string `json:"events,omitempty"`
+	// OnlyOnFailure drops every event except failed and retrying
+	OnlyOnFailure bool `json:"only_on_failure,omitempty"`
+	// MinDurationSeconds drops finished attempts that ran for a shorter time
+	MinDurationSeconds int `json:"min_duration_seconds,omitempty"`
+}
+
+// ParseRules decodes the rules of a decoded notification_config entry
+func ParseRules(raw map[string]interface{}) (Rules, error) {
+	var rules Rules
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return rules, err
+	}
+	err = json.Unmarshal(b, &rules)
+	return rules, err
+}
+
+// ParseEvents splits a comma- or space-separated event list
+func ParseEvents(s string) []string {
+	var events []string
+	for _, e := range strings.FieldsFunc(s, func(c rune) bool { return c == ',' || c == ' ' || c == '\n' }) {
+		events = append(events, strings.ToLower(e))
+	}
+	return events
+}
+
+// Validate reports the first unknown event or invalid threshold
+func (r Rules) Validate() error {
+	for _, event := range r.Events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf("unknown event %q (expected one of %s)", event, strings.Join(Events, ", "))
+		}
+	}
+	if r.MinDurationSeconds < 0 {
+		return errors.New("min_duration_seconds must not be negative")
+	}
+	return nil
+}
+
+// IsZero reports whether the rules leave the default behaviour unchanged
+func (r Rules) IsZero() bool {
+	return len(r.Events) == 0 && !r.OnlyOnFailure && r.MinDurationSeconds == 0
+}
+
+// Match reports whether an event should be sent. duration is how long the
+// attempt ran and only applies to the events that end an attempt.
+func (r Rules) Match(event string, duration time.Duration) bool {
+	events := r.Events
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	if !slices.Contains(events, event) {
+		return false
+	}
+	if r.OnlyOnFailure && event != models.EventFailed && event != models.EventRetrying {
+		return false
+	}
+	if r.MinDurationSeconds > 0 && event != models.EventEnqueued && event != models.EventStarted {
+		if duration < time.Duration(r.MinDurationSeconds)*time.Second {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// MaxSectionLen is the limit Slack puts on the text of a section block
+	MaxSectionLen = 3000
+	// MaxFieldLen is the limit Slack puts on a section field
+	MaxFieldLen = 2000
+	// MaxHeaderLen is the limit Slack puts on the text of a header block
+	MaxHeaderLen = 150
+	// Timeout bounds a single webhook request
+	Timeout = 30 * time.Second
+)
+
+type Client struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+func NewClient(webhookURL string) *Client {
+	return &Client{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: Timeout},
+	}
+}
+
+func (c *Client) SendNotification(resp *models.WebhookResponse) error {
+	status := ":white_check_mark: " + resp.StatusLabel()
+	switch {
+	case !resp.Success:
+		status = ":x: " + resp.StatusLabel()
+	case resp.Event == models.EventEnqueued || resp.Event == models.EventStarted:
+		status = ":hourglass_flowing_sand: " + resp.StatusLabel()
+	}
+
+	var result string
+	if resp.Success {
+		result = "*Response*\n" + TruncateEscaped(Escape(resp.Response), MaxSectionLen-20, "...")
+	} else {
+		result = "*Error*\n" + TruncateEscaped(Escape(resp.Error), MaxSectionLen-20, "...")
+	}
+
+	message := Message{
+		Text: fmt.Sprintf("Claude Code Execution Result: %s", status),
+		Blocks: []Block{
+			{
+				Type: "header",
+				Text: &Text{Type: "plain_text", Text: notifier.Truncate("Claude Code Execution Result", MaxHeaderLen, "...")},
+			},
+			{
+				Type: "section",
+				Fields: []*Text{
+					{Type: "mrkdwn", Text: "*Status*\n" + status},
+					{Type: "mrkdwn", Text: "*Execution Time*\n" + Escape(resp.ExecutionTime)},
+				},
+			},
+			{
+				Type: "section",
+				Text: &Text{Type: "mrkdwn", Text: "*Prompt*\n" + TruncateEscaped(Escape(resp.Prompt), MaxFieldLen, "...")},
+			},
+		},
+	}
+	// Jobs that have not finished yet have nothing to report
+	if resp.Response != "" || resp.Error != "" {
+		message.Blocks = append(message.Blocks, Block{
+			Type: "section",
+			Text: &Text{Type: "mrkdwn", Text: result},
+		})
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	httpResp, err := c.httpClient.Post(c.webhookURL, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to send Slack notification: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := notifier.RetryAfterHeader(httpResp.Header)
+		return &notifier.RetryAfterError{
+			Err:        fmt.Errorf("Slack webhook rate limited"),
+			RetryAfter: retryAfter,
+		}
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		return notifier.StatusError(httpResp.StatusCode, fmt.Errorf("Slack webhook returned unexpected status: %d %s", httpResp.StatusCode, bytes.TrimSpace(body)))
+	}
+
+	return nil
+}
+
+func (c *Client) Name() string {
+	return "slack"
+}
+
+// Escape replaces the characters Slack treats as control sequences in mrkdwn text
+func Escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// TruncateEscaped shortens text escaped with Escape to at most max runes like
+// notifier.Truncate. Slack's limits apply to the escaped text, so it is cut
+// after escaping, and before an entity the cut would otherwise split.
+func TruncateEscaped(s string, max int, suffix string) string {
+	t := notifier.Truncate(s, max, suffix)
+	if t == s {
+		return s
+	}
+	cut := len(t) - len(suffix)
+	if amp := strings.LastIndexByte(s[:cut], '&'); amp >= 0 && !strings.Contains(s[amp:cut], ";") {
+		cut = amp
+	}
+	return s[:cut] + suffix
+}
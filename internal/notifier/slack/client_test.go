@@ -0,0 +1,74 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+func TestSendNotificationEscapedTextFitsBlocks(t *testing.T) {
+	var message Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("invalid message: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	// Every character grows to 4 or 5 when escaped
+	err := NewClient(server.URL).SendNotification(&models.WebhookResponse{
+		Success:  true,
+		Prompt:   strings.Repeat("a<b", 1000),
+		Response: strings.Repeat("<&>", 1000),
+	})
+	if err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+
+	if len(message.Blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(message.Blocks))
+	}
+	for i, block := range message.Blocks {
+		if block.Text == nil {
+			continue
+		}
+		limit := MaxSectionLen
+		if block.Type == "header" {
+			limit = MaxHeaderLen
+		}
+		if n := utf8.RuneCountInString(block.Text.Text); n > limit {
+			t.Errorf("block %d has %d characters, Slack accepts %d", i, n, limit)
+		}
+		// Only whole entities are left once they are removed
+		if rest := strings.NewReplacer("&amp;", "", "&lt;", "", "&gt;", "").Replace(block.Text.Text); strings.ContainsAny(rest, "<>&") {
+			t.Errorf("block %d has unescaped or split entities: %q", i, block.Text.Text)
+		}
+	}
+	if !strings.HasSuffix(message.Blocks[3].Text.Text, "...") {
+		t.Errorf("response block is not marked as truncated: %q", message.Blocks[3].Text.Text)
+	}
+}
+
+func TestTruncateEscaped(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"a &lt; b", 8, "a &lt; b"},
+		{"a &lt; bcd", 9, "a &lt;..."},
+		{"a &lt; b", 7, "a ..."},
+		{"a &amp;&amp;", 10, "a &amp;..."},
+		{"a &amp;&amp;", 8, "a ..."},
+	}
+	for _, tt := range tests {
+		if got := TruncateEscaped(tt.s, tt.max, "..."); got != tt.want {
+			t.Errorf("TruncateEscaped(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package slack
+
+// Message is the payload of a Slack incoming webhook
+type Message struct {
+	// Text is the fallback shown in notifications and clients without Block Kit support
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks,omitempty"`
+}
+
+type Block struct {
+	Type   string  `json:"type"`
+	Text   *Text   `json:"text,omitempty"`
+	Fields []*Text `json:"fields,omitempty"`
+}
+
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
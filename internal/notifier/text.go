@@ -0,0 +1,18 @@
+package notifier
+
+import "unicode/utf8"
+
+// Truncate shortens s to at most max runes, appending suffix, which counts
+// towards max, when s has to be cut. Multi-byte characters are never split.
+func Truncate(s string, max int, suffix string) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	n := max - utf8.RuneCountInString(suffix)
+	cut := 0
+	for i := 0; i < n; i++ {
+		_, size := utf8.DecodeRuneInString(s[cut:])
+		cut += size
+	}
+	return s[:cut] + suffix
+}
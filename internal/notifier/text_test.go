@@ -0,0 +1,23 @@
+package notifier
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s      string
+		max    int
+		suffix string
+		want   string
+	}{
+		{"hello", 5, "...", "hello"},
+		{"hello world", 8, "...", "hello..."},
+		{"hello world", 5, "", "hello"},
+		{"日本語のテキスト", 5, "…", "日本語の…"},
+		{"éééé", 2, "", "éé"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.max, tt.suffix); got != tt.want {
+			t.Errorf("Truncate(%q, %d, %q) = %q, want %q", tt.s, tt.max, tt.suffix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package slackbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// DefaultAPIBaseURL is the Slack Web API the client talks to unless configured otherwise
+	DefaultAPIBaseURL = "https://slack.com/api"
+	// Timeout bounds a single request
+	Timeout = 30 * time.Second
+)
+
+// Client posts replies to Slack. Slash command replies go to the command's
+// response_url; replies to mentions need a bot token for chat.postMessage.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: Timeout},
+	}
+}
+
+// HasToken reports whether the client can call the Web API
+func (c *Client) HasToken() bool {
+	return c.token != ""
+}
+
+// PostResponse posts a message to the response_url of a slash command. A
+// response_url accepts up to five messages within 30 minutes of the command.
+func (c *Client) PostResponse(responseURL string, message Message) error {
+	_, err := c.post(responseURL, "", message)
+	return err
+}
+
+// PostMessage posts a message with chat.postMessage
+func (c *Client) PostMessage(message Message) error {
+	if c.token == "" {
+		return errors.New("Slack bot token is not configured")
+	}
+
+	body, err := c.post(c.baseURL+"/chat.postMessage", c.token, message)
+	if err != nil {
+		return err
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to decode Slack response: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("chat.postMessage failed: %s", resp.Error)
+	}
+	return nil
+}
+
+// post sends message as JSON and returns the response body. Rate limits are
+// returned as a notifier.RetryAfterError.
+func (c *Client) post(url, token string, message Message) ([]byte, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &notifier.RetryAfterError{
+			Err:        errors.New("Slack rate limited the request"),
+			RetryAfter: notifier.RetryAfterHeader(resp.Header),
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, notifier.StatusError(resp.StatusCode, fmt.Errorf("Slack returned unexpected status: %d %s", resp.StatusCode, strings.TrimSpace(string(body))))
+	}
+	return body, nil
+}
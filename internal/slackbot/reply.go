@@ -0,0 +1,79 @@
+package slackbot
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/slack"
+)
+
+const (
+	// NotifierName is the notifier of the outbox deliveries that answer a command or mention
+	NotifierName = "slack_reply"
+	// MaxTextLen is the length beyond which Slack truncates message text
+	MaxTextLen = 40000
+)
+
+// ReplyConfig is the notification config of a reply. Slash commands are answered
+// through ResponseURL, mentions in the thread given by ChannelID and ThreadTS.
+type ReplyConfig struct {
+	ResponseURL string `json:"response_url,omitempty"`
+	ChannelID   string `json:"channel_id,omitempty"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+}
+
+// ReplyNotifier posts a job result as a reply to the Slack message that submitted it
+type ReplyNotifier struct {
+	client *Client
+	config ReplyConfig
+}
+
+func NewReplyNotifier(client *Client, config ReplyConfig) *ReplyNotifier {
+	return &ReplyNotifier{
+		client: client,
+		config: config,
+	}
+}
+
+func (n *ReplyNotifier) SendNotification(resp *models.WebhookResponse) error {
+	text := ReplyText(resp)
+	switch {
+	case n.config.ResponseURL != "":
+		return n.client.PostResponse(n.config.ResponseURL, Message{
+			Text:         text,
+			ResponseType: ResponseTypeInChannel,
+		})
+	case n.config.ChannelID != "":
+		return n.client.PostMessage(Message{
+			Channel:  n.config.ChannelID,
+			ThreadTS: n.config.ThreadTS,
+			Text:     text,
+		})
+	default:
+		return errors.New("Slack reply has neither a response_url nor a channel")
+	}
+}
+
+func (n *ReplyNotifier) Name() string {
+	return NotifierName
+}
+
+// ReplyText renders the result of a job as mrkdwn
+func ReplyText(resp *models.WebhookResponse) string {
+	var text string
+	switch {
+	case resp.Event == models.EventCancelled:
+		text = fmt.Sprintf(":no_entry_sign: Job #%d was cancelled.", resp.JobID)
+	case !resp.Success:
+		text = fmt.Sprintf(":x: *Job #%d failed* after %s\n%s", resp.JobID, resp.ExecutionTime, slack.Escape(resp.Error))
+	default:
+		text = fmt.Sprintf(":white_check_mark: *Job #%d finished* in %s\n%s", resp.JobID, resp.ExecutionTime, slack.Escape(resp.Response))
+	}
+
+	if utf8.RuneCountInString(text) > MaxTextLen {
+		text = slack.TruncateEscaped(text, MaxTextLen-40, "") + "\n\n_(response too long, truncated)_"
+	}
+	return text
+}
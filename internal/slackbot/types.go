@@ -0,0 +1,48 @@
+package slackbot
+
+// Response types of slash command replies
+const (
+	ResponseTypeInChannel = "in_channel"
+	ResponseTypeEphemeral = "ephemeral"
+)
+
+// Events API payload types
+const (
+	PayloadTypeURLVerification = "url_verification"
+	PayloadTypeEventCallback   = "event_callback"
+	EventTypeAppMention        = "app_mention"
+)
+
+// EventPayload is the part of an Events API request the adapter uses
+type EventPayload struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge,omitempty"`
+	EventID   string `json:"event_id,omitempty"`
+	Event     *Event `json:"event,omitempty"`
+}
+
+type Event struct {
+	Type    string `json:"type"`
+	User    string `json:"user"`
+	Text    string `json:"text"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	// ThreadTS is set when the mention was posted in a thread
+	ThreadTS string `json:"thread_ts,omitempty"`
+	// BotID is set for messages posted by bots, including this one
+	BotID string `json:"bot_id,omitempty"`
+}
+
+// Message is a reply posted to a response_url or with chat.postMessage
+type Message struct {
+	Channel      string `json:"channel,omitempty"`
+	ThreadTS     string `json:"thread_ts,omitempty"`
+	Text         string `json:"text"`
+	ResponseType string `json:"response_type,omitempty"`
+}
+
+// apiResponse is the envelope of Web API responses, which report errors with status 200
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
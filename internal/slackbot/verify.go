@@ -0,0 +1,30 @@
+package slackbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// MaxRequestAge is how old a request's timestamp may be before it is rejected as a replay
+const MaxRequestAge = 5 * time.Minute
+
+// Verify checks the X-Slack-Signature header, an HMAC-SHA256 of
+// "v0:<X-Slack-Request-Timestamp>:<body>" keyed with the app's signing secret
+func Verify(signingSecret, signature, timestamp string, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > MaxRequestAge || age < -MaxRequestAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -28,10 +28,14 @@ func GetFileFS(dir string) (fs.FS, error) {
 const (
 	AdminTemplate              = "admin.html"
 	WebhookDetailTemplate      = "webhook_detail.html"
+	JobDetailTemplate          = "job_detail.html"
+	DeadLetterTemplate         = "dead_letter.html"
 	WebhookListItemTemplate    = "html/webhook_list_item.html"
 	APIKeyListItemTemplate     = "html/api_key_list_item.html"
 	GlobalSettingsFormTemplate = "html/global_settings_form.html"
 	NewAPIKeyResponseTemplate  = "html/new_api_key_response.html"
 	SecurityAuditLogItemTemplate = "html/security_audit_log_item.html"
 	JobQueueItemTemplate       = "html/job_queue_item.html"
+	DeadLetterItemTemplate     = "html/dead_letter_item.html"
+	NotificationDeliveryItemTemplate = "html/notification_delivery_item.html"
 )
\ No newline at end of file
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/upamune/claude-code-pull-worker"
+	serviceName         = "claude-code-pull-worker"
+)
+
+// Tracer starts the spans of the worker. Until Setup installs a provider it
+// returns non-recording spans, so tracing costs nothing while it is off.
+var Tracer = otel.Tracer(instrumentationName)
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Setup exports spans over OTLP/HTTP when enabled. The exporter is configured
+// with the standard OTEL_EXPORTER_OTLP_* environment variables and the service
+// name can be overridden with OTEL_SERVICE_NAME. The returned function flushes
+// pending spans and must be called on shutdown.
+func Setup(ctx context.Context, enabled bool) (func(context.Context) error, error) {
+	if !enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := NewProvider(exporter, res)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider returns a tracer provider that batches spans to the exporter
+func NewProvider(exporter sdktrace.SpanExporter, res *resource.Resource) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+}
+
+// Inject serializes the span context of ctx so a job or a notification can
+// continue the trace after it has been picked up from the database
+func Inject(ctx context.Context) sql.NullString {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(carrier)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// Extract returns ctx with the span context stored by Inject, or ctx unchanged
+// if there is none
+func Extract(ctx context.Context, traceContext sql.NullString) context.Context {
+	if !traceContext.Valid {
+		return ctx
+	}
+	var carrier propagation.MapCarrier
+	if err := json.Unmarshal([]byte(traceContext.String), &carrier); err != nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// mcpServerEntry is the on-disk form of a single MCP server, following the
+// format of Claude Code's .mcp.json ("type" defaults to "stdio")
+type mcpServerEntry struct {
+	Type    string            `json:"type"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// ParseMCPServers decodes and validates the mcp_servers JSON stored on a webhook.
+// Both a bare {"name": {...}} map and a .mcp.json style {"mcpServers": {...}} object
+// are accepted. The returned values are MCPStdioServerConfig, MCPSSEServerConfig or
+// MCPHTTPServerConfig. An empty string yields a nil map.
+func ParseMCPServers(raw string) (map[string]MCPServerConfig, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("mcp_servers must be a JSON object: %w", err)
+	}
+	if wrapped, ok := entries["mcpServers"]; ok && len(entries) == 1 {
+		entries = nil
+		if err := json.Unmarshal(wrapped, &entries); err != nil {
+			return nil, fmt.Errorf("mcpServers must be a JSON object: %w", err)
+		}
+	}
+
+	// Validate in a stable order so the same input always reports the same error
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	servers := make(map[string]MCPServerConfig, len(entries))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("mcp server name must not be empty")
+		}
+
+		var entry mcpServerEntry
+		if err := json.Unmarshal(entries[name], &entry); err != nil {
+			return nil, fmt.Errorf("mcp server %q: %w", name, err)
+		}
+
+		server, err := entry.config()
+		if err != nil {
+			return nil, fmt.Errorf("mcp server %q: %w", name, err)
+		}
+		servers[name] = server
+	}
+
+	return servers, nil
+}
+
+func (e mcpServerEntry) config() (MCPServerConfig, error) {
+	switch e.Type {
+	case "", "stdio":
+		if e.Command == "" {
+			return nil, fmt.Errorf("command is required for stdio servers")
+		}
+		if e.URL != "" {
+			return nil, fmt.Errorf("url is not supported for stdio servers")
+		}
+		return MCPStdioServerConfig{Command: e.Command, Args: e.Args, Env: e.Env}, nil
+	case "sse", "http":
+		if e.Command != "" {
+			return nil, fmt.Errorf("command is not supported for %s servers", e.Type)
+		}
+		if err := validateMCPServerURL(e.URL); err != nil {
+			return nil, err
+		}
+		if e.Type == "sse" {
+			return MCPSSEServerConfig{URL: e.URL, Headers: e.Headers}, nil
+		}
+		return MCPHTTPServerConfig{URL: e.URL, Headers: e.Headers}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q (must be stdio, sse or http)", e.Type)
+	}
+}
+
+func validateMCPServerURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	return nil
+}
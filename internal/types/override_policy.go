@@ -0,0 +1,122 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// DefaultMaxAppendSystemPromptLength bounds append_system_prompt overrides when
+// the policy does not set its own limit
+const DefaultMaxAppendSystemPromptLength = 10000
+
+// OverridePolicy is the override_policy stored on a webhook. It lists which
+// Claude options a request may override and within which bounds; options it
+// does not mention cannot be overridden.
+type OverridePolicy struct {
+	// Models a request may choose
+	Models []string `json:"models,omitempty"`
+	// MaxTurns is the highest max_turns a request may set
+	MaxTurns int `json:"max_turns,omitempty"`
+	// AppendSystemPrompt lets requests append up to MaxAppendSystemPromptLength
+	// characters to the system prompt
+	AppendSystemPrompt          bool `json:"append_system_prompt,omitempty"`
+	MaxAppendSystemPromptLength int  `json:"max_append_system_prompt_length,omitempty"`
+	// WorkingDirs a request may run in instead of the webhook's working_dir
+	WorkingDirs []string `json:"working_dirs,omitempty"`
+	// Continue lets requests turn continuing the previous conversation on or off
+	Continue bool `json:"continue,omitempty"`
+	// Resume lets requests resume an earlier session of the webhook by its
+	// session_id or thread
+	Resume bool `json:"resume,omitempty"`
+}
+
+// ParseOverridePolicy decodes and validates the override_policy JSON stored on
+// a webhook. An empty string yields a policy that allows no overrides.
+func ParseOverridePolicy(raw string) (*OverridePolicy, error) {
+	policy := &OverridePolicy{}
+	if strings.TrimSpace(raw) == "" {
+		return policy, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("override_policy must be a JSON object: %w", err)
+	}
+
+	if policy.MaxTurns < 0 {
+		return nil, fmt.Errorf("override_policy max_turns must not be negative")
+	}
+	if policy.MaxAppendSystemPromptLength < 0 {
+		return nil, fmt.Errorf("override_policy max_append_system_prompt_length must not be negative")
+	}
+	for _, model := range policy.Models {
+		if strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("override_policy models must not be empty")
+		}
+	}
+	for i, dir := range policy.WorkingDirs {
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("override_policy working_dirs must be absolute paths: %q", dir)
+		}
+		policy.WorkingDirs[i] = filepath.Clean(dir)
+	}
+	return policy, nil
+}
+
+// Check returns a descriptive error for the first override the policy does not allow
+func (p *OverridePolicy) Check(o *models.OptionOverrides) error {
+	if o == nil {
+		return nil
+	}
+
+	if o.Model != nil {
+		if len(p.Models) == 0 {
+			return fmt.Errorf("this webhook does not allow overriding model")
+		}
+		if !slices.Contains(p.Models, *o.Model) {
+			return fmt.Errorf("model %q is not allowed; allowed models: %s", *o.Model, strings.Join(p.Models, ", "))
+		}
+	}
+
+	if o.MaxTurns != nil {
+		if p.MaxTurns == 0 {
+			return fmt.Errorf("this webhook does not allow overriding max_turns")
+		}
+		if *o.MaxTurns < 1 || *o.MaxTurns > p.MaxTurns {
+			return fmt.Errorf("max_turns must be between 1 and %d, got %d", p.MaxTurns, *o.MaxTurns)
+		}
+	}
+
+	if o.AppendSystemPrompt != nil {
+		if !p.AppendSystemPrompt {
+			return fmt.Errorf("this webhook does not allow overriding append_system_prompt")
+		}
+		limit := p.MaxAppendSystemPromptLength
+		if limit == 0 {
+			limit = DefaultMaxAppendSystemPromptLength
+		}
+		if n := len([]rune(*o.AppendSystemPrompt)); n > limit {
+			return fmt.Errorf("append_system_prompt must be at most %d characters, got %d", limit, n)
+		}
+	}
+
+	if o.WorkingDir != nil {
+		if len(p.WorkingDirs) == 0 {
+			return fmt.Errorf("this webhook does not allow overriding working_dir")
+		}
+		if !filepath.IsAbs(*o.WorkingDir) || !slices.Contains(p.WorkingDirs, filepath.Clean(*o.WorkingDir)) {
+			return fmt.Errorf("working_dir %q is not allowed; allowed directories: %s", *o.WorkingDir, strings.Join(p.WorkingDirs, ", "))
+		}
+	}
+
+	if o.Continue != nil && !p.Continue {
+		return fmt.Errorf("this webhook does not allow overriding continue")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// missingKeyPattern extracts the variable name from the error text/template
+// reports for a missing map key
+var missingKeyPattern = regexp.MustCompile(`map has no entry for key "([^"]*)"`)
+
+// MissingVariableError is returned when a prompt template refers to a variable
+// the caller did not send
+type MissingVariableError struct {
+	Name string
+}
+
+func (e *MissingVariableError) Error() string {
+	return fmt.Sprintf("missing required variable %q", e.Name)
+}
+
+// ParsePromptTemplate parses the prompt_template of a webhook. Every variable
+// referenced as {{.name}} is required; {{index . "name"}} refers to an optional one.
+func ParsePromptTemplate(raw string) (*template.Template, error) {
+	tmpl, err := template.New("prompt_template").Option("missingkey=error").Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt_template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderPromptTemplate renders a prompt template with the variables of a request.
+// A variable missing from variables is reported as a *MissingVariableError.
+func RenderPromptTemplate(raw string, variables map[string]interface{}) (string, error) {
+	tmpl, err := ParsePromptTemplate(raw)
+	if err != nil {
+		return "", err
+	}
+	if variables == nil {
+		variables = map[string]interface{}{}
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, variables); err != nil {
+		var execErr template.ExecError
+		if errors.As(err, &execErr) {
+			if m := missingKeyPattern.FindStringSubmatch(execErr.Err.Error()); m != nil {
+				return "", &MissingVariableError{Name: m[1]}
+			}
+		}
+		return "", fmt.Errorf("failed to render prompt_template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/callback"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+const (
+	// callbackNotifierName is the notifier of the deliveries that report job results to callback URLs
+	callbackNotifierName = "callback"
+	// callbackTimeout bounds a single callback request
+	callbackTimeout = 30 * time.Second
+)
+
+// isFinalJobStatus reports whether a job has reached a state it will not leave
+func isFinalJobStatus(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+// callbackConfig is the config of a callback delivery
+type callbackConfig struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// enqueueCallback queues the report of a job's final outcome to the caller's
+// callback URL, if it has one, but not of failures that will be retried
+func enqueueCallback(ctx context.Context, queries *db.Queries, job *db.JobQueue, response *models.WebhookResponse, metadata models.JobMetadata) error {
+	if !job.CallbackUrl.Valid || job.CallbackUrl.String == "" || !isFinalJobStatus(metadata.Status) {
+		return nil
+	}
+
+	config, err := json.Marshal(callbackConfig{URL: job.CallbackUrl.String, Secret: job.CallbackSecret.String})
+	if err != nil {
+		return err
+	}
+	return enqueueNotifications(ctx, queries, []notificationTarget{{notifier: callbackNotifierName, config: config}}, response, metadata)
+}
+
+// callbackNotifier POSTs a job result to the job's callback URL and records every
+// attempt in callback_deliveries, which the job page lists
+type callbackNotifier struct {
+	ctx     context.Context
+	client  *callback.Client
+	queries *db.Queries
+	config  callbackConfig
+	jobID   int64
+	attempt int64
+}
+
+func (n *callbackNotifier) SendNotification(response *models.WebhookResponse) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return notifier.Permanent(fmt.Errorf("failed to marshal callback: %w", err))
+	}
+
+	statusCode, err := n.client.Deliver(n.ctx, n.config.URL, n.config.Secret, n.jobID, body)
+	delivery := db.CreateCallbackDeliveryParams{
+		JobID:      n.jobID,
+		Url:        n.config.URL,
+		Attempt:    n.attempt,
+		StatusCode: sql.NullInt64{Int64: int64(statusCode), Valid: statusCode != 0},
+		Success:    err == nil,
+	}
+	if err != nil {
+		delivery.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+	}
+	if recordErr := n.queries.CreateCallbackDelivery(n.ctx, delivery); recordErr != nil {
+		slog.ErrorContext(n.ctx, "Failed to record callback delivery", "error", recordErr)
+	}
+
+	// A forbidden address stays forbidden, so retrying is pointless
+	if errors.Is(err, callback.ErrForbiddenAddress) {
+		return notifier.Permanent(err)
+	}
+	if err != nil && statusCode != 0 {
+		return notifier.StatusError(statusCode, err)
+	}
+	return err
+}
+
+func (n *callbackNotifier) Name() string {
+	return callbackNotifierName
+}
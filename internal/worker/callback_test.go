@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/callback"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+func TestCallbackIsDeliveredFromOutbox(t *testing.T) {
+	signed := make(chan bool, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signed <- r.Header.Get(callback.SignatureHeader) == callback.Sign("s3cret", body)
+	}))
+	defer receiver.Close()
+
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	job, err := queries.EnqueueJob(ctx, db.EnqueueJobParams{
+		WebhookID:      "test",
+		Prompt:         "hello",
+		CallbackUrl:    sql.NullString{String: receiver.URL, Valid: true},
+		CallbackSecret: sql.NullString{String: "s3cret", Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the final outcome is reported
+	if err := queueJobNotifications(ctx, queries, &job, models.EventEnqueued, nil, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if deliveries, _ := queries.ListNotificationDeliveries(ctx, job.ID); len(deliveries) != 0 {
+		t.Fatalf("got %d deliveries for a pending job, want none", len(deliveries))
+	}
+	if _, err := queries.CancelJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := queueJobNotifications(ctx, queries, &job, models.EventCancelled, nil, ErrJobCancelled, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewNotificationDispatcher(queries)
+	u, _ := url.Parse(receiver.URL)
+	d.callbacks.SetAllowedHosts([]string{u.Hostname()})
+	d.dispatchDue(ctx)
+	d.wg.Wait()
+
+	deliveries, err := queries.ListNotificationDeliveries(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Notifier != callbackNotifierName || deliveries[0].Status != "delivered" {
+		t.Fatalf("deliveries = %+v, want one delivered callback", deliveries)
+	}
+	if !<-signed {
+		t.Error("the callback was not signed with the job's secret")
+	}
+	attempts, err := queries.ListCallbackDeliveries(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || !attempts[0].Success || attempts[0].Attempt != 1 {
+		t.Errorf("callback attempts = %+v, want one successful attempt", attempts)
+	}
+}
+
+func TestCallbackToForbiddenAddressIsNotRetried(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	job, err := queries.EnqueueJob(ctx, db.EnqueueJobParams{
+		WebhookID:   "test",
+		Prompt:      "hello",
+		CallbackUrl: sql.NullString{String: "http://127.0.0.1:1/hook", Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queries.CancelJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := queueJobNotifications(ctx, queries, &job, models.EventCancelled, nil, ErrJobCancelled, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewNotificationDispatcher(queries)
+	d.dispatchDue(ctx)
+	d.wg.Wait()
+
+	deliveries, err := queries.ListNotificationDeliveries(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != "failed" || deliveries[0].Attempts != 1 {
+		t.Fatalf("deliveries = %+v, want one failed callback", deliveries)
+	}
+}
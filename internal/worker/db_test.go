@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/database"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+)
+
+// newTestQueries returns queries on a fresh database with the schema and the
+// default settings applied, and an active webhook named test
+func newTestQueries(t *testing.T) *db.Queries {
+	t.Helper()
+	conn, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	for _, file := range []string{"../../sql/schema.sql", "../../sql/seed.sql"} {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(string(script)); err != nil {
+			t.Fatalf("failed to apply %s: %v", file, err)
+		}
+	}
+	if _, err := conn.Exec("INSERT INTO webhooks (id, name) VALUES ('test', 'test')"); err != nil {
+		t.Fatal(err)
+	}
+	return db.New(conn)
+}
+
+// enqueueTestJob adds a job for the test webhook
+func enqueueTestJob(t *testing.T, queries *db.Queries, prompt string) db.JobQueue {
+	t.Helper()
+	job, err := queries.EnqueueJob(context.Background(), db.EnqueueJobParams{WebhookID: "test", Prompt: prompt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
@@ -0,0 +1,11 @@
+package worker
+
+import "context"
+
+// Hooks for the tests of package worker_test, which drive the worker together with the handlers
+
+var NewTestQueries = newTestQueries
+
+func (w *QueueWorker) ProcessNextJob(ctx context.Context) {
+	w.processNextJob(ctx)
+}
@@ -0,0 +1,481 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/callback"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/discordbot"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/metrics"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/discord"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/email"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/generichttp"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier/slack"
+	"github.com/upamune/claude-code-pull-worker/internal/slackbot"
+	"github.com/upamune/claude-code-pull-worker/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// notificationMaxAttempts is how often a notification is tried before it is marked failed
+	notificationMaxAttempts = 8
+	// notificationConcurrency bounds how many notifications are sent at the same time
+	notificationConcurrency = 4
+)
+
+// notificationRetryPolicy spaces out retries of failed notifications, reaching
+// about an hour in total over notificationMaxAttempts
+var notificationRetryPolicy = RetryPolicy{
+	Base:   30 * time.Second,
+	Factor: 2,
+	Jitter: 0.2,
+	Max:    30 * time.Minute,
+}
+
+// notifierTypes are the notification_config keys in the order their notifiers are queued
+var notifierTypes = []string{"discord", "slack", "email", "generic_http"}
+
+// notificationTarget is one configured notifier, its notification_config entry
+// and the rules deciding which events it receives
+type notificationTarget struct {
+	notifier string
+	config   []byte
+	rules    notifier.Rules
+}
+
+// notificationPayload is stored with each delivery so it can be sent again later
+type notificationPayload struct {
+	Response *models.WebhookResponse `json:"response"`
+	Job      models.JobMetadata      `json:"job"`
+}
+
+// slackConfig is the "slack" entry of a notification config
+type slackConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// notificationTargets returns every notifier configured in config
+func notificationTargets(ctx context.Context, config map[string]interface{}) []notificationTarget {
+	var targets []notificationTarget
+	for _, name := range notifierTypes {
+		raw, ok := config[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		b, err := json.Marshal(raw)
+		if err != nil {
+			continue
+		}
+		if _, err := newNotifier(name, b, models.JobMetadata{}); err != nil {
+			continue
+		}
+		rules, err := notifier.ParseRules(raw)
+		if err != nil {
+			slog.WarnContext(ctx, "Ignoring invalid notification rules", "notifier", name, "error", err)
+		}
+		targets = append(targets, notificationTarget{notifier: name, config: b, rules: rules})
+	}
+	return targets
+}
+
+// newNotifier builds the notifier named by a notification_config key from its entry.
+// job is passed to notifiers that render their payload from templates.
+func newNotifier(name string, config []byte, job models.JobMetadata) (notifier.Notifier, error) {
+	switch name {
+	case "discord":
+		var c discord.Config
+		if err := json.Unmarshal(config, &c); err != nil {
+			return nil, err
+		}
+		if c.WebhookURL == "" {
+			return nil, errors.New("discord webhook_url is not set")
+		}
+		return discord.NewClient(c), nil
+	case "slack":
+		var c slackConfig
+		if err := json.Unmarshal(config, &c); err != nil {
+			return nil, err
+		}
+		if c.WebhookURL == "" {
+			return nil, errors.New("slack webhook_url is not set")
+		}
+		return slack.NewClient(c.WebhookURL), nil
+	case "email":
+		var c email.Config
+		if err := json.Unmarshal(config, &c); err != nil {
+			return nil, err
+		}
+		if c.Host == "" {
+			return nil, errors.New("email host is not set")
+		}
+		return email.NewClient(c), nil
+	case "generic_http":
+		var c generichttp.Config
+		if err := json.Unmarshal(config, &c); err != nil {
+			return nil, err
+		}
+		if c.URL == "" {
+			return nil, errors.New("generic_http url is not set")
+		}
+		return generichttp.NewClient(c, job), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", name)
+	}
+}
+
+// matchingTargets returns the targets whose rules accept the event
+func matchingTargets(targets []notificationTarget, event string, duration time.Duration) []notificationTarget {
+	var matched []notificationTarget
+	for _, target := range targets {
+		if target.rules.Match(event, duration) {
+			matched = append(matched, target)
+		}
+	}
+	return matched
+}
+
+// enqueueNotifications writes one delivery per target to the notification outbox.
+// Each delivery keeps the span context and request ID of ctx so sending it joins
+// the job's trace and logs.
+func enqueueNotifications(ctx context.Context, queries *db.Queries, targets []notificationTarget, response *models.WebhookResponse, job models.JobMetadata) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(notificationPayload{Response: response, Job: job})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	for _, target := range targets {
+		if err := queries.CreateNotificationDelivery(ctx, db.CreateNotificationDeliveryParams{
+			JobID:        job.ID,
+			Notifier:     target.notifier,
+			Config:       string(target.config),
+			Payload:      string(payload),
+			MaxAttempts:  notificationMaxAttempts,
+			TraceContext: tracing.Inject(ctx),
+			RequestID:    sql.NullString{String: logging.RequestID(ctx), Valid: logging.RequestID(ctx) != ""},
+		}); err != nil {
+			return fmt.Errorf("failed to queue %s notification: %w", target.notifier, err)
+		}
+	}
+	return nil
+}
+
+// enqueueDiscordReply queues the reply to the Discord command a job was submitted
+// with, if any, once the job has finished
+func enqueueDiscordReply(ctx context.Context, queries *db.Queries, response *models.WebhookResponse, job models.JobMetadata) error {
+	if response.Event != models.EventSucceeded && response.Event != models.EventFailed && response.Event != models.EventCancelled {
+		return nil
+	}
+
+	reply, err := queries.GetDiscordReply(ctx, job.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Discord reply: %w", err)
+	}
+
+	config, err := json.Marshal(discordbot.ReplyConfig{
+		ChannelID: reply.ChannelID,
+		MessageID: reply.MessageID.String,
+	})
+	if err != nil {
+		return err
+	}
+	return enqueueNotifications(ctx, queries, []notificationTarget{{notifier: discordbot.NotifierName, config: config}}, response, job)
+}
+
+// enqueueSlackReply queues the reply to the Slack command or mention a job was
+// submitted with, if any, once the job has finished
+func enqueueSlackReply(ctx context.Context, queries *db.Queries, response *models.WebhookResponse, job models.JobMetadata) error {
+	if response.Event != models.EventSucceeded && response.Event != models.EventFailed && response.Event != models.EventCancelled {
+		return nil
+	}
+
+	reply, err := queries.GetSlackReply(ctx, job.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Slack reply: %w", err)
+	}
+
+	config, err := json.Marshal(slackbot.ReplyConfig{
+		ResponseURL: reply.ResponseUrl.String,
+		ChannelID:   reply.ChannelID.String,
+		ThreadTS:    reply.ThreadTs.String,
+	})
+	if err != nil {
+		return err
+	}
+	return enqueueNotifications(ctx, queries, []notificationTarget{{notifier: slackbot.NotifierName, config: config}}, response, job)
+}
+
+// enqueueGitHubReply queues the comment with the result of a job triggered from
+// a GitHub issue or pull request, if any, once the job has finished
+func enqueueGitHubReply(ctx context.Context, queries *db.Queries, response *models.WebhookResponse, job models.JobMetadata) error {
+	if response.Event != models.EventSucceeded && response.Event != models.EventFailed && response.Event != models.EventCancelled {
+		return nil
+	}
+
+	reply, err := queries.GetGitHubReply(ctx, job.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub reply: %w", err)
+	}
+
+	config, err := json.Marshal(githubbot.ReplyConfig{
+		Repository:  reply.Repository,
+		IssueNumber: reply.IssueNumber,
+	})
+	if err != nil {
+		return err
+	}
+	return enqueueNotifications(ctx, queries, []notificationTarget{{notifier: githubbot.NotifierName, config: config}}, response, job)
+}
+
+// NotificationDispatcher sends the deliveries queued in notification_deliveries,
+// retrying failures with exponential backoff or the delay a rate-limited service asked for
+type NotificationDispatcher struct {
+	queries *db.Queries
+	// discord answers Discord commands; nil when the bot is not configured
+	discord *discordbot.Client
+	// slack answers Slack commands and mentions; nil when Slack is not configured
+	slack *slackbot.Client
+	// github comments on GitHub issues and pull requests; nil when no token is configured
+	github githubbot.Commenter
+	// callbacks reports job results to the callback URLs of the jobs
+	callbacks *callback.Client
+	stopCh    chan struct{}
+	slots     chan struct{}
+	wg        sync.WaitGroup
+}
+
+func NewNotificationDispatcher(queries *db.Queries) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		queries:   queries,
+		callbacks: callback.NewClient(callbackTimeout),
+		stopCh:    make(chan struct{}),
+		slots:     make(chan struct{}, notificationConcurrency),
+	}
+}
+
+// Start requeues deliveries interrupted by a previous shutdown and then sends
+// due deliveries until ctx is cancelled or Stop is called
+func (d *NotificationDispatcher) Start(ctx context.Context) {
+	if err := d.queries.ResetSendingNotificationDeliveries(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset interrupted notification deliveries", "error", err)
+	}
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	defer d.wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.dispatchDue(ctx)
+		}
+	}
+}
+
+func (d *NotificationDispatcher) Stop() {
+	close(d.stopCh)
+}
+
+// dispatchDue claims due deliveries and sends each in its own goroutine while a slot is free
+func (d *NotificationDispatcher) dispatchDue(ctx context.Context) {
+	for {
+		select {
+		case d.slots <- struct{}{}:
+		default:
+			return
+		}
+
+		delivery, err := d.queries.ClaimNotificationDelivery(ctx)
+		if err != nil {
+			<-d.slots
+			if err != sql.ErrNoRows {
+				slog.ErrorContext(ctx, "Failed to claim notification delivery", "error", err)
+			}
+			return
+		}
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			defer func() { <-d.slots }()
+			d.deliver(ctx, delivery)
+		}()
+	}
+}
+
+// deliver makes one attempt at sending a claimed delivery and records the outcome
+func (d *NotificationDispatcher) deliver(ctx context.Context, delivery db.NotificationDelivery) {
+	ctx = logging.With(ctx, "delivery_id", delivery.ID, "job_id", delivery.JobID, "notifier", delivery.Notifier)
+	if delivery.RequestID.Valid {
+		ctx = logging.WithRequestID(ctx, delivery.RequestID.String)
+	}
+
+	var payload notificationPayload
+	if err := json.Unmarshal([]byte(delivery.Payload), &payload); err != nil {
+		d.fail(ctx, delivery, fmt.Errorf("invalid payload: %w", err), false)
+		return
+	}
+	ctx = logging.With(ctx, "webhook_id", payload.Job.WebhookID)
+
+	n, err := d.newNotifier(ctx, delivery, payload.Job)
+	if err != nil {
+		d.fail(ctx, delivery, err, false)
+		return
+	}
+
+	_, span := tracing.Tracer.Start(tracing.Extract(ctx, delivery.TraceContext), "Notifier.SendNotification",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("notifier", delivery.Notifier),
+			attribute.Int64("job.id", delivery.JobID),
+			attribute.Int64("notification.attempt", delivery.Attempts),
+		),
+	)
+	// A notifier posting several messages resumes after those an earlier attempt posted
+	if partial, ok := n.(notifier.PartialSender); ok {
+		var sent int
+		sent, err = partial.SendNotificationFrom(payload.Response, int(delivery.SentMessages))
+		delivery.SentMessages = int64(sent)
+	} else {
+		err = n.SendNotification(payload.Response)
+	}
+	tracing.End(span, err)
+	if err != nil {
+		d.fail(ctx, delivery, err, notifier.IsRetryable(err))
+		return
+	}
+
+	observeDelivery(delivery.Notifier, metrics.ResultDelivered)
+	if err := d.queries.CompleteNotificationDelivery(ctx, delivery.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to mark notification delivery as delivered", "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Notification delivered")
+}
+
+// newNotifier builds the notifier of a delivery, including replies to Discord, Slack
+// and GitHub and job callbacks
+func (d *NotificationDispatcher) newNotifier(ctx context.Context, delivery db.NotificationDelivery, job models.JobMetadata) (notifier.Notifier, error) {
+	switch delivery.Notifier {
+	case callbackNotifierName:
+		var config callbackConfig
+		if err := json.Unmarshal([]byte(delivery.Config), &config); err != nil {
+			return nil, err
+		}
+		return &callbackNotifier{
+			ctx:     ctx,
+			client:  d.callbacks,
+			queries: d.queries,
+			config:  config,
+			jobID:   delivery.JobID,
+			attempt: delivery.Attempts,
+		}, nil
+	case discordbot.NotifierName:
+		if d.discord == nil || !d.discord.HasToken() {
+			return nil, errors.New("Discord bot token is not configured")
+		}
+		var config discordbot.ReplyConfig
+		if err := json.Unmarshal([]byte(delivery.Config), &config); err != nil {
+			return nil, err
+		}
+		return discordbot.NewReplyNotifier(d.discord, config), nil
+	case slackbot.NotifierName:
+		if d.slack == nil {
+			return nil, errors.New("Slack integration is not configured")
+		}
+		var config slackbot.ReplyConfig
+		if err := json.Unmarshal([]byte(delivery.Config), &config); err != nil {
+			return nil, err
+		}
+		if config.ResponseURL == "" && !d.slack.HasToken() {
+			return nil, errors.New("Slack bot token is not configured")
+		}
+		return slackbot.NewReplyNotifier(d.slack, config), nil
+	case githubbot.NotifierName:
+		if d.github == nil {
+			return nil, errors.New("GitHub token is not configured")
+		}
+		var config githubbot.ReplyConfig
+		if err := json.Unmarshal([]byte(delivery.Config), &config); err != nil {
+			return nil, err
+		}
+		return githubbot.NewReplyNotifier(d.github, config), nil
+	default:
+		return newNotifier(delivery.Notifier, []byte(delivery.Config), job)
+	}
+}
+
+// fail records a failed attempt and schedules the next one unless the error is
+// not retryable or the delivery has used up its attempts
+func (d *NotificationDispatcher) fail(ctx context.Context, delivery db.NotificationDelivery, err error, retryable bool) {
+	status := "failed"
+	result := metrics.ResultFailed
+	var delay time.Duration
+	if retryable && delivery.Attempts < delivery.MaxAttempts {
+		status = "pending"
+		result = metrics.ResultRetrying
+		delay = notificationRetryPolicy.backoff(delivery.Attempts - 1)
+
+		// A rate-limited service says exactly how long to wait
+		var rateLimited *notifier.RetryAfterError
+		if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+			delay = rateLimited.RetryAfter
+		}
+	}
+
+	observeDelivery(delivery.Notifier, result)
+	if recordErr := d.queries.FailNotificationDelivery(ctx, db.FailNotificationDeliveryParams{
+		Status:       status,
+		LastError:    sql.NullString{String: err.Error(), Valid: true},
+		DelaySeconds: int64(math.Ceil(delay.Seconds())),
+		SentMessages: delivery.SentMessages,
+		ID:           delivery.ID,
+	}); recordErr != nil {
+		slog.ErrorContext(ctx, "Failed to record notification delivery failure", "error", recordErr)
+		return
+	}
+
+	if status == "failed" {
+		slog.ErrorContext(ctx, "Giving up on notification", "attempts", delivery.Attempts, "error", err)
+		return
+	}
+	slog.WarnContext(ctx, "Notification failed, retrying", "attempt", delivery.Attempts, "max_attempts", delivery.MaxAttempts, "delay", delay.String(), "error", err)
+}
+
+// observeDelivery counts the outcome of an attempt at a delivery. Callbacks are
+// counted in their own metric too.
+func observeDelivery(name, result string) {
+	metrics.NotificationDeliveries.WithLabelValues(name, result).Inc()
+	if name == callbackNotifierName {
+		metrics.CallbackDeliveries.WithLabelValues(result).Inc()
+	}
+}
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/notifier"
+)
+
+// stubCommenter records the comments it is asked to post
+type stubCommenter struct {
+	mu       sync.Mutex
+	comments []stubComment
+	err      error
+}
+
+type stubComment struct {
+	repository string
+	number     int64
+	body       string
+}
+
+func (c *stubCommenter) CreateComment(ctx context.Context, repository string, number int64, body string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.comments = append(c.comments, stubComment{repository, number, body})
+	return c.err
+}
+
+// dispatchGitHubReply finishes a job triggered from octo/repo#7 and runs the dispatcher once
+func dispatchGitHubReply(t *testing.T, commenter *stubCommenter) (*db.Queries, db.JobQueue) {
+	t.Helper()
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	job := enqueueTestJob(t, queries, "Fix the crash")
+	if err := queries.CreateGitHubReply(ctx, db.CreateGitHubReplyParams{JobID: job.ID, Repository: "octo/repo", IssueNumber: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	response := "Fixed in a1b2c3"
+	if err := queueJobNotifications(ctx, queries, &job, models.EventSucceeded, &response, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewNotificationDispatcher(queries)
+	d.github = commenter
+	d.dispatchDue(ctx)
+	d.wg.Wait()
+	return queries, job
+}
+
+func TestNotificationDispatcherCommentsGitHubReply(t *testing.T) {
+	commenter := &stubCommenter{}
+	queries, job := dispatchGitHubReply(t, commenter)
+
+	if len(commenter.comments) != 1 {
+		t.Fatalf("posted %d comments, want 1", len(commenter.comments))
+	}
+	comment := commenter.comments[0]
+	if comment.repository != "octo/repo" || comment.number != 7 {
+		t.Errorf("commented on %s#%d, want octo/repo#7", comment.repository, comment.number)
+	}
+	if !strings.HasPrefix(comment.body, githubbot.CommentMarker) || !strings.Contains(comment.body, "Fixed in a1b2c3") {
+		t.Errorf("comment body = %q", comment.body)
+	}
+
+	deliveries, err := queries.ListNotificationDeliveries(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Notifier != githubbot.NotifierName || deliveries[0].Status != "delivered" {
+		t.Errorf("deliveries = %+v, want one delivered %s delivery", deliveries, githubbot.NotifierName)
+	}
+}
+
+func TestNotificationDispatcherRetriesFailedGitHubReply(t *testing.T) {
+	commenter := &stubCommenter{err: errors.New("GitHub API returned 502")}
+	queries, job := dispatchGitHubReply(t, commenter)
+
+	deliveries, err := queries.ListNotificationDeliveries(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != "pending" || delivery.Attempts != 1 || delivery.LastError.String != "GitHub API returned 502" {
+		t.Errorf("delivery = %+v, want pending for a retry", delivery)
+	}
+}
+
+func TestNotificationDispatcherGivesUpOnRejectedGitHubReply(t *testing.T) {
+	commenter := &stubCommenter{err: notifier.Permanent(errors.New("GitHub API returned 404"))}
+	queries, job := dispatchGitHubReply(t, commenter)
+
+	deliveries, err := queries.ListNotificationDeliveries(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != "failed" || delivery.Attempts != 1 {
+		t.Errorf("delivery = %+v, want failed without a retry", delivery)
+	}
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/discordbot"
+	"github.com/upamune/claude-code-pull-worker/internal/githubbot"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+	"github.com/upamune/claude-code-pull-worker/internal/slackbot"
+)
+
+// Pool runs several QueueWorkers so jobs of independent webhooks can run in parallel.
+// Per-webhook concurrency and working directory exclusivity are enforced by DequeueJob.
+type Pool struct {
+	queries       *db.Queries
+	workers       []*QueueWorker
+	notifications *NotificationDispatcher
+	wg            sync.WaitGroup
+}
+
+func NewPool(queries *db.Queries, size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+
+	workers := make([]*QueueWorker, size)
+	for i := range workers {
+		workers[i] = NewQueueWorker(queries)
+	}
+
+	return &Pool{
+		queries:       queries,
+		workers:       workers,
+		notifications: NewNotificationDispatcher(queries),
+	}
+}
+
+// Start resets stale jobs once and then runs every worker and the notification
+// dispatcher until ctx is cancelled
+func (p *Pool) Start(ctx context.Context) {
+	if err := p.queries.ResetStaleJobs(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset stale jobs", "error", err)
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.notifications.Start(ctx)
+	}()
+
+	slog.InfoContext(ctx, "Starting worker pool", "workers", len(p.workers))
+	for _, w := range p.workers {
+		p.wg.Add(1)
+		go func(w *QueueWorker) {
+			defer p.wg.Done()
+			w.Start(ctx)
+		}(w)
+	}
+	p.wg.Wait()
+}
+
+// SetDiscordClient lets the pool post job results back to the Discord commands that submitted them
+func (p *Pool) SetDiscordClient(client *discordbot.Client) {
+	p.notifications.discord = client
+}
+
+// SetSlackClient lets the pool post job results back to the Slack commands and mentions that submitted them
+func (p *Pool) SetSlackClient(client *slackbot.Client) {
+	p.notifications.slack = client
+}
+
+// SetGitHubCommenter lets the pool comment job results on the GitHub issues and pull requests that triggered them
+func (p *Pool) SetGitHubCommenter(commenter githubbot.Commenter) {
+	p.notifications.github = commenter
+}
+
+// SetCallbackAllowedHosts lets job callbacks reach the given hosts even on internal addresses
+func (p *Pool) SetCallbackAllowedHosts(hosts []string) {
+	p.notifications.callbacks.SetAllowedHosts(hosts)
+}
+
+// CancelJob marks a pending or processing job as cancelled, queues its notifications
+// and aborts it if one of the pool's workers is running it. It returns sql.ErrNoRows
+// if the job cannot be cancelled.
+func (p *Pool) CancelJob(ctx context.Context, jobID int64) (db.JobQueue, error) {
+	var job db.JobQueue
+	err := p.queries.InTx(ctx, func(q *db.Queries) error {
+		var err error
+		if job, err = q.CancelJob(ctx, jobID); err != nil {
+			return err
+		}
+		// A running job reports how long it ran until it was cancelled
+		var executionTime time.Duration
+		if job.StartedAt.Valid {
+			executionTime = time.Since(job.StartedAt.Time)
+		}
+		return queueJobNotifications(jobContext(ctx, job), q, &job, models.EventCancelled, nil, ErrJobCancelled, executionTime)
+	})
+	if err != nil {
+		return job, err
+	}
+
+	for _, w := range p.workers {
+		if w.CancelRunningJob(jobID) {
+			return job, nil
+		}
+	}
+	slog.InfoContext(jobContext(ctx, job), "Job cancelled before it started")
+	return job, nil
+}
+
+// Stop signals every worker and the notification dispatcher to stop and waits for them to return
+func (p *Pool) Stop() {
+	for _, w := range p.workers {
+		w.Stop()
+	}
+	p.notifications.Stop()
+	p.wg.Wait()
+}
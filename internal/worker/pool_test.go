@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/models"
+)
+
+// deliveryEvents returns the events of the notifications queued for a job, in order
+func deliveryEvents(t *testing.T, queries *db.Queries, jobID int64) []string {
+	t.Helper()
+	deliveries, err := queries.ListNotificationDeliveries(context.Background(), jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	for _, delivery := range deliveries {
+		var payload notificationPayload
+		if err := json.Unmarshal([]byte(delivery.Payload), &payload); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, payload.Response.Event)
+	}
+	return events
+}
+
+// newNotifiedTestQueries returns test queries whose jobs notify a generic HTTP endpoint of every event
+func newNotifiedTestQueries(t *testing.T) *db.Queries {
+	t.Helper()
+	queries := newTestQueries(t)
+	if err := queries.UpdateGlobalSetting(context.Background(), db.UpdateGlobalSettingParams{
+		SettingKey:   "default_notification_config",
+		SettingValue: `{"generic_http":{"url":"http://example.com/hook","events":["enqueued","started","succeeded","failed","retrying","cancelled"]}}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return queries
+}
+
+func TestCancelJobQueuesNotification(t *testing.T) {
+	ctx := context.Background()
+	queries := newNotifiedTestQueries(t)
+	pool := NewPool(queries, 1)
+	job := enqueueTestJob(t, queries, "cancel me")
+
+	if _, err := pool.CancelJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if events := deliveryEvents(t, queries, job.ID); len(events) != 1 || events[0] != models.EventCancelled {
+		t.Fatalf("events = %v, want [%s]", events, models.EventCancelled)
+	}
+
+	// A job that cannot be cancelled any more is not notified again
+	if _, err := pool.CancelJob(ctx, job.ID); err != sql.ErrNoRows {
+		t.Fatalf("err = %v, want sql.ErrNoRows", err)
+	}
+	if events := deliveryEvents(t, queries, job.ID); len(events) != 1 {
+		t.Fatalf("events = %v, want one", events)
+	}
+}
+
+func TestProcessNextJobQueuesNotificationsWithStatus(t *testing.T) {
+	// Without a claude binary the attempt fails
+	t.Setenv("PATH", t.TempDir()+string(os.PathListSeparator))
+	ctx := context.Background()
+	queries := newNotifiedTestQueries(t)
+	job := enqueueTestJob(t, queries, "hello")
+
+	NewQueueWorker(queries).processNextJob(ctx)
+
+	// The notifications are queued by the time the outcome is recorded
+	current, err := queries.GetJobStatus(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.EventRetrying
+	if isFinalJobStatus(current.JobStatus) {
+		want = models.EventFailed
+	}
+	if events := deliveryEvents(t, queries, job.ID); len(events) != 2 || events[0] != models.EventStarted || events[1] != want {
+		t.Fatalf("events = %v, want [%s %s]", events, models.EventStarted, want)
+	}
+}
@@ -4,23 +4,44 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/upamune/claude-code-pull-worker/internal/db"
 	"github.com/upamune/claude-code-pull-worker/internal/executor"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/metrics"
 	"github.com/upamune/claude-code-pull-worker/internal/models"
-	"github.com/upamune/claude-code-pull-worker/internal/notifier"
-	"github.com/upamune/claude-code-pull-worker/internal/notifier/discord"
+	"github.com/upamune/claude-code-pull-worker/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// heartbeatInterval must stay well below the 10 minute visibility timeout set by DequeueJob
+const heartbeatInterval = 1 * time.Minute
+
+// ErrJobCancelled is reported to notifiers when a job is cancelled before it finishes
+var ErrJobCancelled = errors.New("job cancelled")
+
+// errJobLost means the job was cancelled, or dequeued again by another worker after
+// its visibility timed out, while the worker was running it, so the worker must
+// not report an outcome for it
+var errJobLost = errors.New("job is no longer owned by this worker")
+
 type QueueWorker struct {
 	id       string
 	queries  *db.Queries
 	executor *executor.ClaudeExecutor
 	stopCh   chan struct{}
+
+	// The job currently being processed and a function to abort it
+	mu            sync.Mutex
+	currentJobID  int64
+	cancelCurrent context.CancelFunc
 }
 
 func NewQueueWorker(queries *db.Queries) *QueueWorker {
@@ -33,12 +54,10 @@ func NewQueueWorker(queries *db.Queries) *QueueWorker {
 }
 
 func (w *QueueWorker) Start(ctx context.Context) {
-	log.Printf("Queue worker %s started", w.id)
-	
-	// Reset stale jobs on startup
-	if err := w.queries.ResetStaleJobs(ctx); err != nil {
-		log.Printf("Failed to reset stale jobs: %v", err)
-	}
+	ctx = logging.With(ctx, "worker_id", w.id)
+	slog.InfoContext(ctx, "Queue worker started")
+	metrics.WorkerStarted()
+	defer metrics.WorkerStopped()
 	
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -46,10 +65,10 @@ func (w *QueueWorker) Start(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("Queue worker %s stopping due to context cancellation", w.id)
+			slog.InfoContext(ctx, "Queue worker stopping due to context cancellation")
 			return
 		case <-w.stopCh:
-			log.Printf("Queue worker %s stopping", w.id)
+			slog.InfoContext(ctx, "Queue worker stopping")
 			return
 		case <-ticker.C:
 			w.processNextJob(ctx)
@@ -63,32 +82,185 @@ func (w *QueueWorker) Stop() {
 
 func (w *QueueWorker) processNextJob(ctx context.Context) {
 	// Try to dequeue a job
-	job, err := w.queries.DequeueJob(ctx, sql.NullString{String: w.id, Valid: true})
+	dequeueStart := time.Now()
+	var job db.JobQueue
+	err := w.queries.InTx(ctx, func(q *db.Queries) error {
+		var err error
+		if job, err = q.DequeueJob(ctx, sql.NullString{String: w.id, Valid: true}); err != nil {
+			return err
+		}
+		// The "started" notifications are queued with the status change, so they
+		// are sent if and only if the job really started
+		return queueJobNotifications(jobContext(ctx, job), q, &job, models.EventStarted, nil, nil, 0)
+	})
 	if err != nil {
 		if err != sql.ErrNoRows {
-			log.Printf("Failed to dequeue job: %v", err)
+			slog.ErrorContext(ctx, "Failed to dequeue job", "error", err)
 		}
 		return
 	}
 	
-	log.Printf("Worker %s processing job %d for webhook %s", w.id, job.ID, job.WebhookID)
+	// Continue the trace started when the job was enqueued. The dequeue span is
+	// only started once the job, and so the trace it belongs to, is known.
+	attrs := trace.WithAttributes(
+		attribute.Int64("job.id", job.ID),
+		attribute.String("webhook.id", job.WebhookID),
+		attribute.Int64("job.retry_count", job.RetryCount),
+	)
+	ctx = jobContext(ctx, job)
+	_, dequeueSpan := tracing.Tracer.Start(ctx, "DequeueJob", trace.WithTimestamp(dequeueStart), attrs)
+	dequeueSpan.End()
+	ctx, span := tracing.Tracer.Start(ctx, "QueueWorker.processJob", attrs)
+	defer func() { tracing.End(span, err) }()
+	
+	slog.InfoContext(ctx, "Processing job", "retry_count", job.RetryCount)
+	metrics.WorkerBusy()
+	defer metrics.WorkerIdle()
+	
+	// Make the running job cancellable from outside the worker
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	w.setCurrentJob(job.ID, cancelJob)
+	defer w.setCurrentJob(0, nil)
+	
+	// Keep the job invisible to other workers while it is running
+	heartbeatCtx, stopHeartbeat := context.WithCancel(jobCtx)
+	go w.heartbeat(heartbeatCtx, job.ID, cancelJob)
 	
 	// Process the job
 	startTime := time.Now()
-	err = w.processJob(ctx, &job)
+	err = w.processJob(jobCtx, &job)
 	executionTime := time.Since(startTime)
+	stopHeartbeat()
+	cancelJob()
+	
+	if err == nil {
+		metrics.ObserveJob(job.WebhookID, metrics.OutcomeSucceeded, executionTime)
+		return
+	}
+	
+	// A cancelled job has already left the processing state, so it must not be retried
+	if current, statusErr := w.queries.GetJobStatus(context.Background(), job.ID); statusErr == nil && current.JobStatus == "cancelled" {
+		slog.InfoContext(ctx, "Job cancelled")
+		// Pool.CancelJob queued the notifications when it cancelled the job
+		metrics.ObserveJob(job.WebhookID, metrics.OutcomeCancelled, executionTime)
+		return
+	}
+	
+	// The job finished but was taken from this worker first; its result is dropped
+	if errors.Is(err, errJobLost) {
+		slog.WarnContext(ctx, "Job is no longer owned by this worker, dropping its result")
+		return
+	}
+	
+	retryable := executor.IsRetryable(err)
+	var backoff time.Duration
+	if retryable {
+		backoff = w.retryPolicy(ctx, job.WebhookID).backoff(job.RetryCount)
+	}
+	
+	// The failure, the attempt kept for the dead-letter view and the notifications
+	// are recorded together
+	failErr := w.queries.InTx(ctx, func(q *db.Queries) error {
+		failed, recordErr := q.FailJob(ctx, db.FailJobParams{
+			Retryable:      retryable,
+			BackoffSeconds: int64(backoff.Seconds()),
+			ErrorMessage:   sql.NullString{String: err.Error(), Valid: true},
+			ID:             job.ID,
+			WorkerID:       sql.NullString{String: w.id, Valid: true},
+		})
+		if recordErr != nil {
+			return recordErr
+		}
+		if failed == 0 {
+			return errJobLost
+		}
+		if recordErr := q.CreateJobAttempt(ctx, db.CreateJobAttemptParams{
+			JobID:        job.ID,
+			ErrorMessage: err.Error(),
+			Retryable:    retryable,
+		}); recordErr != nil {
+			return fmt.Errorf("failed to record job attempt: %w", recordErr)
+		}
+		return queueJobNotifications(ctx, q, &job, models.EventFailed, nil, err, executionTime)
+	})
+	if errors.Is(failErr, errJobLost) {
+		// Another worker may be running the job again after its visibility timed out
+		slog.WarnContext(ctx, "Job is no longer owned by this worker, dropping its failure", "error", err)
+		return
+	}
+	if failErr != nil {
+		slog.ErrorContext(ctx, "Failed to mark job as failed", "error", failErr)
+		return
+	}
+	
+	if retryable {
+		slog.WarnContext(ctx, "Job failed, retrying", "error", err, "backoff", backoff.String())
+	} else {
+		slog.ErrorContext(ctx, "Job failed permanently", "error", err)
+	}
 	
+	// FailJob puts the job back to pending on the same condition
+	outcome := metrics.OutcomeFailed
+	if retryable && job.RetryCount < job.MaxRetries {
+		outcome = metrics.OutcomeRetrying
+	}
+	metrics.ObserveJob(job.WebhookID, outcome, executionTime)
+}
+
+// retryPolicy returns the backoff configuration of the job's webhook
+func (w *QueueWorker) retryPolicy(ctx context.Context, webhookID string) RetryPolicy {
+	webhook, err := w.queries.GetWebhook(ctx, webhookID)
 	if err != nil {
-		log.Printf("Job %d failed: %v", job.ID, err)
-		if err := w.queries.FailJob(ctx, db.FailJobParams{
-			ID:           job.ID,
-			ErrorMessage: sql.NullString{String: err.Error(), Valid: true},
-		}); err != nil {
-			log.Printf("Failed to mark job as failed: %v", err)
+		return DefaultRetryPolicy
+	}
+	return RetryPolicyFromWebhook(webhook)
+}
+
+func (w *QueueWorker) setCurrentJob(jobID int64, cancel context.CancelFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.currentJobID = jobID
+	w.cancelCurrent = cancel
+}
+
+// CancelRunningJob aborts the given job if this worker is processing it
+func (w *QueueWorker) CancelRunningJob(jobID int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.currentJobID != jobID || w.cancelCurrent == nil {
+		return false
+	}
+	w.cancelCurrent()
+	return true
+}
+
+// heartbeat extends the visibility timeout of a running job until ctx is cancelled.
+// If the job is no longer processing (e.g. it was cancelled elsewhere) the job is aborted.
+func (w *QueueWorker) heartbeat(ctx context.Context, jobID int64, cancelJob context.CancelFunc) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := w.queries.ExtendJobVisibility(ctx, db.ExtendJobVisibilityParams{
+				ID:       jobID,
+				WorkerID: sql.NullString{String: w.id, Valid: true},
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to extend job visibility", "error", err)
+				}
+				continue
+			}
+			if n == 0 {
+				slog.WarnContext(ctx, "Job is no longer owned by this worker, aborting")
+				cancelJob()
+				return
+			}
 		}
-		
-		// Send failure notification
-		go w.sendJobNotification(context.Background(), &job, nil, err, executionTime)
 	}
 }
 
@@ -96,58 +268,171 @@ func (w *QueueWorker) processJob(ctx context.Context, job *db.JobQueue) error {
 	// Get webhook details
 	_, err := w.queries.GetWebhook(ctx, job.WebhookID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			// The webhook was deleted while the job was queued
+			return executor.Permanent(fmt.Errorf("failed to get webhook: %w", err))
+		}
 		return fmt.Errorf("failed to get webhook: %w", err)
 	}
 	
 	// Execute Claude with job options
-	output, err := w.executor.ExecuteWithOptions(ctx, job.Prompt, *job)
+	result, err := w.execute(ctx, job)
+	// Failed attempts are paid for too, so their usage counts against budgets
+	w.recordUsage(ctx, job.ID, result.Usage)
 	if err != nil {
 		return fmt.Errorf("Claude execution failed: %w", err)
 	}
+	output := result.Response
+	sessionID := sql.NullString{String: result.SessionID, Valid: result.SessionID != ""}
 	
 	// Mark job as completed
 	executionTimeMs := time.Since(job.StartedAt.Time).Milliseconds()
-	if err := w.queries.CompleteJob(ctx, db.CompleteJobParams{
-		ID:              job.ID,
-		Response:        sql.NullString{String: output, Valid: true},
-		ExecutionTimeMs: sql.NullInt64{Int64: executionTimeMs, Valid: true},
-	}); err != nil {
-		return fmt.Errorf("failed to complete job: %w", err)
+	err = w.queries.InTx(ctx, func(q *db.Queries) error {
+		completed, err := q.CompleteJob(ctx, db.CompleteJobParams{
+			ID:              job.ID,
+			Response:        sql.NullString{String: output, Valid: true},
+			ExecutionTimeMs: sql.NullInt64{Int64: executionTimeMs, Valid: true},
+			SessionID:       sessionID,
+			WorkerID:        sql.NullString{String: w.id, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to complete job: %w", err)
+		}
+		if completed == 0 {
+			return errJobLost
+		}
+		return queueJobNotifications(ctx, q, job, models.EventSucceeded, &output, nil, time.Duration(executionTimeMs)*time.Millisecond)
+	})
+	if err != nil {
+		return err
 	}
 	
 	// Also create execution history for backward compatibility
-	_, err = w.queries.CreateExecutionHistory(ctx, db.CreateExecutionHistoryParams{
-		WebhookID:       job.WebhookID,
-		ApiKeyID:        job.ApiKeyID,
-		Prompt:          job.Prompt,
-		Response:        sql.NullString{String: output, Valid: true},
-		Error:           sql.NullString{},
-		Success:         true,
-		ExecutionTimeMs: sql.NullInt64{Int64: executionTimeMs, Valid: true},
+	historyCtx, historySpan := tracing.Tracer.Start(ctx, "CreateExecutionHistory")
+	_, err = w.queries.CreateExecutionHistory(historyCtx, db.CreateExecutionHistoryParams{
+		WebhookID:                job.WebhookID,
+		ApiKeyID:                 job.ApiKeyID,
+		Prompt:                   job.Prompt,
+		Response:                 sql.NullString{String: output, Valid: true},
+		Error:                    sql.NullString{},
+		Success:                  true,
+		ExecutionTimeMs:          sql.NullInt64{Int64: executionTimeMs, Valid: true},
+		SessionID:                sessionID,
+		InputTokens:              result.Usage.InputTokens,
+		OutputTokens:             result.Usage.OutputTokens,
+		CacheReadInputTokens:     result.Usage.CacheReadInputTokens,
+		CacheCreationInputTokens: result.Usage.CacheCreationInputTokens,
+		TotalCostUsd:             result.Usage.TotalCostUSD,
+		NumTurns:                 result.Usage.NumTurns,
+		DurationMs:               result.Usage.DurationMs,
 	})
+	tracing.End(historySpan, err)
 	if err != nil {
-		log.Printf("Failed to create execution history: %v", err)
+		slog.ErrorContext(ctx, "Failed to create execution history", "error", err)
 	}
 	
-	// Send success notification
-	go w.sendJobNotification(context.Background(), job, &output, nil, time.Duration(executionTimeMs)*time.Millisecond)
-	
-	log.Printf("Job %d completed successfully", job.ID)
+	slog.InfoContext(ctx, "Job completed successfully")
 	return nil
 }
 
 
-func (w *QueueWorker) sendJobNotification(ctx context.Context, job *db.JobQueue, response *string, err error, executionTime time.Duration) {
+// execute runs Claude for the job in a span that records the session and usage
+func (w *QueueWorker) execute(ctx context.Context, job *db.JobQueue) (executor.Result, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "ClaudeExecutor.ExecuteWithOptions")
+	result, err := w.executor.ExecuteWithOptions(ctx, job.Prompt, *job)
+	span.SetAttributes(
+		attribute.String("claude.session_id", result.SessionID),
+		attribute.Int64("claude.input_tokens", result.Usage.InputTokens),
+		attribute.Int64("claude.output_tokens", result.Usage.OutputTokens),
+		attribute.Float64("claude.total_cost_usd", result.Usage.TotalCostUSD),
+		attribute.Int64("claude.num_turns", result.Usage.NumTurns),
+	)
+	tracing.End(span, err)
+	return result, err
+}
+
+// recordUsage adds the usage of an attempt to its job, and keeps it in job_usage
+// where budgets still count it after the job is discarded
+func (w *QueueWorker) recordUsage(ctx context.Context, jobID int64, usage executor.Usage) {
+	if usage == (executor.Usage{}) {
+		return
+	}
+	if err := w.queries.AddJobUsage(ctx, db.AddJobUsageParams{
+		InputTokens:              usage.InputTokens,
+		OutputTokens:             usage.OutputTokens,
+		CacheReadInputTokens:     usage.CacheReadInputTokens,
+		CacheCreationInputTokens: usage.CacheCreationInputTokens,
+		TotalCostUsd:             usage.TotalCostUSD,
+		NumTurns:                 usage.NumTurns,
+		DurationMs:               usage.DurationMs,
+		ID:                       jobID,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to record job usage", "error", err)
+	}
+	if err := w.queries.CreateJobUsage(ctx, db.CreateJobUsageParams{
+		InputTokens:              usage.InputTokens,
+		OutputTokens:             usage.OutputTokens,
+		CacheReadInputTokens:     usage.CacheReadInputTokens,
+		CacheCreationInputTokens: usage.CacheCreationInputTokens,
+		TotalCostUsd:             usage.TotalCostUSD,
+		NumTurns:                 usage.NumTurns,
+		DurationMs:               usage.DurationMs,
+		JobID:                    jobID,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to record attempt usage", "error", err)
+	}
+}
+
+// jobContext continues the trace started when the job was enqueued and logs with
+// the job and the ID of the request that submitted it
+func jobContext(ctx context.Context, job db.JobQueue) context.Context {
+	ctx = logging.With(tracing.Extract(ctx, job.TraceContext), "job_id", job.ID, "webhook_id", job.WebhookID)
+	if job.RequestID.Valid {
+		ctx = logging.WithRequestID(ctx, job.RequestID.String)
+	}
+	return ctx
+}
+
+// NotifyJobEnqueued queues the "enqueued" notifications of a job that was just
+// added to the queue. queries must be bound to the transaction that added it.
+func NotifyJobEnqueued(ctx context.Context, queries *db.Queries, job db.JobQueue) error {
+	return queueJobNotifications(ctx, queries, &job, models.EventEnqueued, nil, nil, 0)
+}
+
+// queueJobNotifications queues the notifications of a job event with queries bound
+// to the transaction that recorded the event. A failure is reported as retrying
+// while the job waits for another attempt. Nothing is queued for a job or webhook
+// that no longer exists.
+func queueJobNotifications(ctx context.Context, queries *db.Queries, job *db.JobQueue, event string, response *string, err error, executionTime time.Duration) error {
 	// Get webhook for notification config
-	webhook, webhookErr := w.queries.GetWebhook(ctx, job.WebhookID)
+	webhook, webhookErr := queries.GetWebhook(ctx, job.WebhookID)
+	if webhookErr == sql.ErrNoRows {
+		return nil
+	}
 	if webhookErr != nil {
-		log.Printf("Failed to get webhook for notification: %v", webhookErr)
-		return
+		return fmt.Errorf("failed to get webhook for notification: %w", webhookErr)
+	}
+	
+	// Describe the job as it is now, after the outcome has been recorded
+	current, statusErr := queries.GetJobStatus(ctx, job.ID)
+	if statusErr == sql.ErrNoRows {
+		return nil
+	}
+	if statusErr != nil {
+		return fmt.Errorf("failed to get job status for notification: %w", statusErr)
+	}
+	status := current.JobStatus
+	if event == models.EventFailed && !isFinalJobStatus(status) {
+		event = models.EventRetrying
 	}
 	
 	// Create webhook response object
 	webhookResponse := models.NewWebhookResponse(job.Prompt, err == nil)
+	webhookResponse.JobID = job.ID
+	webhookResponse.Event = event
 	webhookResponse.ExecutionTime = fmt.Sprintf("%.2fs", executionTime.Seconds())
+	webhookResponse.Thread = job.Thread.String
+	webhookResponse.SessionID = current.SessionID.String
 	
 	if err != nil {
 		webhookResponse.Error = err.Error()
@@ -155,61 +440,65 @@ func (w *QueueWorker) sendJobNotification(ctx context.Context, job *db.JobQueue,
 		webhookResponse.Response = *response
 	}
 	
-	// Send notifications (reuse existing notification logic)
-	w.sendNotifications(ctx, &webhook, webhookResponse)
-}
-
-func (w *QueueWorker) sendNotifications(ctx context.Context, webhook *db.Webhook, response *models.WebhookResponse) {
-	// Parse notification config
-	var notifConfig map[string]interface{}
-	if notifBytes, ok := webhook.NotificationConfig.([]byte); ok {
-		if err := json.Unmarshal(notifBytes, &notifConfig); err != nil {
-			return
-		}
-	} else if notifStr, ok := webhook.NotificationConfig.(string); ok {
-		if err := json.Unmarshal([]byte(notifStr), &notifConfig); err != nil {
-			return
-		}
+	jobMetadata := models.JobMetadata{
+		ID:          job.ID,
+		WebhookID:   webhook.ID,
+		WebhookName: webhook.Name,
+		Status:      status,
+		RetryCount:  current.RetryCount,
+		MaxRetries:  current.MaxRetries,
+		CreatedAt:   job.CreatedAt.UTC().Format(time.RFC3339),
 	}
 	
-	// Build notifiers based on config
-	var notifiers []notifier.Notifier
-	
-	// Check for Discord config
-	if discordConfig, ok := notifConfig["discord"].(map[string]interface{}); ok {
-		if webhookURL, ok := discordConfig["webhook_url"].(string); ok && webhookURL != "" {
-			notifiers = append(notifiers, discord.NewClient(webhookURL))
-		}
+	if err := queueNotifications(ctx, queries, &webhook, webhookResponse, jobMetadata, executionTime); err != nil {
+		return err
+	}
+	if err := enqueueDiscordReply(ctx, queries, webhookResponse, jobMetadata); err != nil {
+		return err
 	}
+	if err := enqueueSlackReply(ctx, queries, webhookResponse, jobMetadata); err != nil {
+		return err
+	}
+	if err := enqueueGitHubReply(ctx, queries, webhookResponse, jobMetadata); err != nil {
+		return err
+	}
+	return enqueueCallback(ctx, queries, job, webhookResponse, jobMetadata)
+}
+
+// queueNotifications queues the response for every notifier of the webhook whose
+// rules accept its event, falling back to the global notifiers when the webhook has none
+func queueNotifications(ctx context.Context, queries *db.Queries, webhook *db.Webhook, response *models.WebhookResponse, job models.JobMetadata, executionTime time.Duration) error {
+	// Collect notifiers based on the webhook's own config
+	targets := notificationTargets(ctx, parseNotificationConfig(webhook.NotificationConfig))
 	
 	// If no webhook-specific config, check global settings
-	if len(notifiers) == 0 {
-		globalNotif, err := w.queries.GetGlobalSetting(ctx, "default_notification_config")
+	if len(targets) == 0 {
+		globalNotif, err := queries.GetGlobalSetting(ctx, "default_notification_config")
 		if err == nil {
-			var globalConfig map[string]interface{}
-			if globalBytes, ok := globalNotif.([]byte); ok {
-				if err := json.Unmarshal(globalBytes, &globalConfig); err == nil {
-					if discordConfig, ok := globalConfig["discord"].(map[string]interface{}); ok {
-						if webhookURL, ok := discordConfig["webhook_url"].(string); ok && webhookURL != "" {
-							notifiers = append(notifiers, discord.NewClient(webhookURL))
-						}
-					}
-				}
-			} else if globalStr, ok := globalNotif.(string); ok {
-				if err := json.Unmarshal([]byte(globalStr), &globalConfig); err == nil {
-					if discordConfig, ok := globalConfig["discord"].(map[string]interface{}); ok {
-						if webhookURL, ok := discordConfig["webhook_url"].(string); ok && webhookURL != "" {
-							notifiers = append(notifiers, discord.NewClient(webhookURL))
-						}
-					}
-				}
-			}
+			targets = notificationTargets(ctx, parseNotificationConfig(globalNotif))
 		}
 	}
 	
-	// Send notifications
-	if len(notifiers) > 0 {
-		multiNotifier := notifier.NewMultiNotifier(notifiers...)
-		multiNotifier.SendNotification(response)
+	// Queue the notifications; the dispatcher sends them and retries failures
+	return enqueueNotifications(ctx, queries, matchingTargets(targets, response.Event, executionTime), response, job)
+}
+
+// parseNotificationConfig decodes a notification_config value as stored in SQLite,
+// returning nil when it is empty or not valid JSON
+func parseNotificationConfig(value interface{}) map[string]interface{} {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return nil
 	}
-}
\ No newline at end of file
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/executor"
+)
+
+func TestRecordUsageSurvivesDiscard(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	w := NewQueueWorker(queries)
+	job := enqueueTestJob(t, queries, "expensive")
+	since := job.CreatedAt.Add(-time.Minute)
+
+	// Two attempts, the second one failing for good
+	w.recordUsage(ctx, job.ID, executor.Usage{InputTokens: 100, TotalCostUSD: 0.25, NumTurns: 2})
+	w.recordUsage(ctx, job.ID, executor.Usage{InputTokens: 50, TotalCostUSD: 0.5, NumTurns: 1})
+	if _, err := queries.DequeueJob(ctx, sql.NullString{String: w.id, Valid: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queries.FailJob(ctx, db.FailJobParams{
+		ErrorMessage: sql.NullString{String: "boom", Valid: true},
+		ID:           job.ID,
+		WorkerID:     sql.NullString{String: w.id, Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := queries.GetJobStatus(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.TotalCostUsd != 0.75 || current.InputTokens != 150 {
+		t.Errorf("job usage = $%v, %d input tokens; want the sum of both attempts", current.TotalCostUsd, current.InputTokens)
+	}
+
+	if n, err := queries.DiscardJob(ctx, job.ID); err != nil || n != 1 {
+		t.Fatalf("DiscardJob = %d, %v", n, err)
+	}
+
+	cost, err := queries.GetWebhookCost(ctx, db.GetWebhookCostParams{WebhookID: "test", JobCreatedAt: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 0.75 {
+		t.Errorf("cost after discarding the job = $%v, want $0.75", cost)
+	}
+	stats, err := queries.GetWebhookUsageStats(ctx, db.GetWebhookUsageStatsParams{WebhookID: "test", JobCreatedAt: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.InputTokens != 150 || stats.NumTurns != 3 {
+		t.Errorf("usage stats = %+v, want the discarded job's usage", stats)
+	}
+
+	// Only jobs created since the start of a period count towards it
+	cost, err = queries.GetWebhookCost(ctx, db.GetWebhookCostParams{WebhookID: "test", JobCreatedAt: job.CreatedAt.Add(time.Minute)})
+	if err != nil || cost != 0 {
+		t.Errorf("cost of a later period = $%v, %v; want $0", cost, err)
+	}
+}
+
+func TestDequeueJobWorkingDir(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	if _, err := queries.CreateWebhook(ctx, db.CreateWebhookParams{ID: "other", Name: "other", NotificationConfig: "{}", MaxConcurrency: 1}); err != nil {
+		t.Fatal(err)
+	}
+	enqueue := func(webhookID, workingDir string) db.JobQueue {
+		t.Helper()
+		job, err := queries.EnqueueJob(ctx, db.EnqueueJobParams{
+			WebhookID:  webhookID,
+			Prompt:     "p",
+			WorkingDir: sql.NullString{String: workingDir, Valid: workingDir != ""},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+	dequeue := func() (int64, bool) {
+		t.Helper()
+		job, err := queries.DequeueJob(ctx, sql.NullString{String: "worker-1", Valid: true})
+		if err == sql.ErrNoRows {
+			return 0, false
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job.ID, true
+	}
+
+	// Jobs without a working directory do not exclude each other
+	first, second := enqueue("test", ""), enqueue("other", "")
+	for _, want := range []int64{first.ID, second.ID} {
+		if id, ok := dequeue(); !ok || id != want {
+			t.Fatalf("dequeued job %d, %v; want %d", id, ok, want)
+		}
+	}
+	for _, job := range []db.JobQueue{first, second} {
+		if _, err := queries.CompleteJob(ctx, db.CompleteJobParams{ID: job.ID, WorkerID: sql.NullString{String: "worker-1", Valid: true}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Jobs sharing one run one at a time, even across webhooks
+	shared := enqueue("test", "/srv/app")
+	enqueue("other", "/srv/app")
+	if id, ok := dequeue(); !ok || id != shared.ID {
+		t.Fatalf("dequeued job %d, %v; want %d", id, ok, shared.ID)
+	}
+	if id, ok := dequeue(); ok {
+		t.Errorf("dequeued job %d while another job runs in /srv/app", id)
+	}
+}
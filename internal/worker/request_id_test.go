@@ -0,0 +1,113 @@
+package worker_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/handlers"
+	"github.com/upamune/claude-code-pull-worker/internal/logging"
+	"github.com/upamune/claude-code-pull-worker/internal/worker"
+)
+
+// syncBuffer is a bytes.Buffer that the worker and the dispatcher can log to concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records returns the messages of the JSON records logged with the given request ID
+func (b *syncBuffer) records(t *testing.T, requestID string) map[string]bool {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seen := map[string]bool{}
+	for _, line := range bytes.Split(bytes.TrimSpace(b.buf.Bytes()), []byte("\n")) {
+		var record struct {
+			Msg       string `json:"msg"`
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		if record.RequestID == requestID {
+			seen[record.Msg] = true
+		}
+	}
+	return seen
+}
+
+func TestJobLogsRequestID(t *testing.T) {
+	var logs syncBuffer
+	defer slog.SetDefault(slog.Default())
+	if err := logging.Setup(&logs, "info", "json"); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte(fakeClaude), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	queries := worker.NewTestQueries(t)
+	ctx := context.Background()
+	if err := queries.UpdateGlobalSetting(ctx, db.UpdateGlobalSettingParams{
+		SettingKey:   "default_notification_config",
+		SettingValue: `{"generic_http":{"url":"` + receiver.URL + `"}}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/test", bytes.NewReader([]byte(`{"prompt":"hello"}`)))
+	req.Header.Set(logging.RequestIDHeader, "req-42")
+	req = mux.SetURLVars(req, map[string]string{"uuid": "test"})
+	rec := httptest.NewRecorder()
+	logging.Middleware(http.HandlerFunc(handlers.NewWebhookExecutionHandler(queries).HandleWebhookExecution)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// The job is processed and notified long after the request has returned,
+	// but its records still carry the request's ID
+	worker.NewQueueWorker(queries).ProcessNextJob(ctx)
+	dispatcher := worker.NewNotificationDispatcher(queries)
+	stopped := make(chan struct{})
+	go func() {
+		dispatcher.Start(ctx)
+		close(stopped)
+	}()
+	for deadline := time.Now().Add(10 * time.Second); !logs.records(t, "req-42")["Notification delivered"]; time.Sleep(100 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("no notification was delivered with request_id req-42")
+		}
+	}
+	dispatcher.Stop()
+	<-stopped
+
+	seen := logs.records(t, "req-42")
+	for _, msg := range []string{"Job enqueued", "Processing job", "Job completed successfully"} {
+		if !seen[msg] {
+			t.Errorf("no %q record with request_id req-42 was logged", msg)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+)
+
+// RetryPolicy controls how long a failed job waits before its next attempt.
+// The delay grows as Base * Factor^attempt, is spread by ±Jitter (a fraction
+// of the delay) and is capped at Max.
+type RetryPolicy struct {
+	Base   time.Duration
+	Factor float64
+	Jitter float64
+	Max    time.Duration
+}
+
+// DefaultRetryPolicy matches the column defaults of the webhooks table
+var DefaultRetryPolicy = RetryPolicy{
+	Base:   30 * time.Second,
+	Factor: 2,
+	Jitter: 0.2,
+	Max:    1 * time.Hour,
+}
+
+// RetryPolicyFromWebhook builds the retry policy configured on a webhook
+func RetryPolicyFromWebhook(webhook db.Webhook) RetryPolicy {
+	return RetryPolicy{
+		Base:   time.Duration(webhook.RetryBackoffBaseSeconds) * time.Second,
+		Factor: webhook.RetryBackoffFactor,
+		Jitter: webhook.RetryBackoffJitter,
+		Max:    time.Duration(webhook.RetryBackoffMaxSeconds) * time.Second,
+	}
+}
+
+// Backoff returns the delay before retry number attempt (0 for the first retry).
+// random must return values in [0, 1); it is a parameter so the jitter is deterministic in tests.
+func (p RetryPolicy) Backoff(attempt int64, random func() float64) time.Duration {
+	if p.Base <= 0 {
+		return 0
+	}
+
+	factor := p.Factor
+	if factor < 1 {
+		factor = 1
+	}
+	delay := float64(p.Base) * math.Pow(factor, float64(attempt))
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay *= 1 + jitter*(2*random()-1)
+	}
+
+	if p.Max > 0 && delay > float64(p.Max) {
+		delay = float64(p.Max)
+	}
+	return time.Duration(delay)
+}
+
+// backoff returns the delay before the next attempt of a job using random jitter
+func (p RetryPolicy) backoff(attempt int64) time.Duration {
+	return p.Backoff(attempt, rand.Float64)
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Base: 10 * time.Second, Factor: 2, Jitter: 0.5, Max: time.Minute}
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int64
+		random  float64
+		want    time.Duration
+	}{
+		{"base without jitter", policy, 0, 0.5, 10 * time.Second},
+		{"factor growth", policy, 2, 0.5, 40 * time.Second},
+		{"lowest jitter", policy, 1, 0, 10 * time.Second},
+		{"highest jitter", policy, 1, 0.999999, 30 * time.Second},
+		{"capped at max", policy, 3, 0.5, time.Minute},
+		{"jitter does not exceed max", policy, 2, 0.999999, time.Minute},
+		{"jitter above 1 is clamped", RetryPolicy{Base: 10 * time.Second, Factor: 1, Jitter: 3}, 0, 0, 0},
+		{"factor below 1 keeps the base", RetryPolicy{Base: 10 * time.Second, Factor: 0.5}, 3, 0.5, 10 * time.Second},
+		{"no max", RetryPolicy{Base: time.Second, Factor: 10}, 4, 0.5, 10000 * time.Second},
+		{"zero base", RetryPolicy{Factor: 2, Jitter: 0.5, Max: time.Minute}, 3, 0.9, 0},
+		{"negative base", RetryPolicy{Base: -time.Second, Factor: 2}, 1, 0.5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Backoff(tt.attempt, func() float64 { return tt.random })
+			if diff := got - tt.want; diff < -time.Millisecond || diff > time.Millisecond {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoffJitterRange(t *testing.T) {
+	policy := RetryPolicy{Base: 10 * time.Second, Factor: 2, Jitter: 0.2, Max: time.Hour}
+
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(1)
+		if got < 16*time.Second || got > 24*time.Second {
+			t.Fatalf("backoff(1) = %v, want within 20s ±20%%", got)
+		}
+	}
+}
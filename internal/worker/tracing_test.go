@@ -0,0 +1,141 @@
+package worker_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/upamune/claude-code-pull-worker/internal/db"
+	"github.com/upamune/claude-code-pull-worker/internal/handlers"
+	"github.com/upamune/claude-code-pull-worker/internal/tracing"
+	"github.com/upamune/claude-code-pull-worker/internal/worker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeClaude answers like the claude CLI with stream-json output
+const fakeClaude = `#!/bin/sh
+cat > /dev/null
+echo '{"type":"system","subtype":"init","session_id":"sess-1","cwd":"/tmp","model":"fake","tools":[]}'
+echo '{"type":"assistant","session_id":"sess-1","message":{"content":[{"type":"text","text":"done"}]}}'
+echo '{"type":"result","subtype":"success","is_error":false,"num_turns":1,"result":"done","session_id":"sess-1","total_cost_usd":0.01,"duration_ms":10,"usage":{"input_tokens":1,"output_tokens":1}}'
+`
+
+// tracing.Tracer keeps delegating to the first provider installed globally, so
+// the test installs one provider per process and empties its exporter
+var (
+	exporter       = tracetest.NewInMemoryExporter()
+	provider       = tracing.NewProvider(exporter, resource.Default())
+	installTracing sync.Once
+)
+
+func TestJobTrace(t *testing.T) {
+	installTracing.Do(func() { otel.SetTracerProvider(provider) })
+	// Drop the spans of earlier tests, including those still batched
+	provider.ForceFlush(context.Background())
+	exporter.Reset()
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte(fakeClaude), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var mu sync.Mutex
+	var notified []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Event string `json:"event"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		notified = append(notified, body.Event)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	queries := worker.NewTestQueries(t)
+	ctx := context.Background()
+	if err := queries.UpdateGlobalSetting(ctx, db.UpdateGlobalSettingParams{
+		SettingKey:   "default_notification_config",
+		SettingValue: `{"generic_http":{"url":"` + receiver.URL + `","body":"{\"event\": {{json .Event}}}"}}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enqueue through the webhook endpoint, which starts the trace
+	req := httptest.NewRequest(http.MethodPost, "/webhook/test", bytes.NewReader([]byte(`{"prompt":"hello"}`)))
+	req = mux.SetURLVars(req, map[string]string{"uuid": "test"})
+	rec := httptest.NewRecorder()
+	handlers.NewWebhookExecutionHandler(queries).HandleWebhookExecution(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	worker.NewQueueWorker(queries).ProcessNextJob(ctx)
+	job, err := queries.GetJobStatus(ctx, 1)
+	if err != nil || job.JobStatus != "completed" {
+		t.Fatalf("job = %+v, %v; want completed", job, err)
+	}
+
+	// The dispatcher sends the notifications from the outbox
+	dispatcher := worker.NewNotificationDispatcher(queries)
+	stopped := make(chan struct{})
+	go func() {
+		dispatcher.Start(ctx)
+		close(stopped)
+	}()
+	succeeded := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Contains(notified, "succeeded")
+	}
+	for deadline := time.Now().Add(10 * time.Second); !succeeded(); time.Sleep(100 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the succeeded notification was not delivered")
+		}
+	}
+
+	// Stopping waits for deliveries in flight, whose spans end after the request
+	dispatcher.Stop()
+	<-stopped
+
+	if err := provider.ForceFlush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+	var root string
+	for _, span := range spans {
+		if span.Name == "HandleWebhookExecution" {
+			root = span.SpanContext.TraceID().String()
+		}
+	}
+	if root == "" {
+		t.Fatal("no HandleWebhookExecution span was exported")
+	}
+
+	// Every span of the job, including those started from job_queue.trace_context
+	// and notification_deliveries.trace_context, belongs to the request's trace
+	seen := map[string]bool{}
+	for _, span := range spans {
+		seen[span.Name] = true
+		if id := span.SpanContext.TraceID().String(); id != root {
+			t.Errorf("%s span has trace %s, want %s", span.Name, id, root)
+		}
+	}
+	for _, name := range []string{"DequeueJob", "QueueWorker.processJob", "ClaudeExecutor.ExecuteWithOptions", "CreateExecutionHistory", "Notifier.SendNotification"} {
+		if !seen[name] {
+			t.Errorf("no %s span was exported", name)
+		}
+	}
+}
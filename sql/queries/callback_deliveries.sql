@@ -0,0 +1,8 @@
+-- name: CreateCallbackDelivery :exec
+INSERT INTO callback_deliveries (job_id, url, attempt, status_code, error_message, success)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListCallbackDeliveries :many
+SELECT * FROM callback_deliveries
+WHERE job_id = ?
+ORDER BY id ASC;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- name: GetWebhookByDiscordChannel :one
+SELECT w.* FROM webhooks w
+JOIN discord_channels dc ON dc.webhook_id = w.id
+WHERE dc.channel_id = ? AND w.is_active = 1;
+
+-- name: ListDiscordChannels :many
+SELECT channel_id FROM discord_channels
+WHERE webhook_id = ?
+ORDER BY channel_id;
+
+-- name: CreateDiscordChannel :exec
+INSERT INTO discord_channels (channel_id, webhook_id)
+VALUES (?, ?);
+
+-- name: DeleteDiscordChannels :exec
+DELETE FROM discord_channels WHERE webhook_id = ?;
+
+-- name: CreateDiscordReply :exec
+-- Remembers the channel a job was submitted from so its result can be posted back
+INSERT INTO discord_replies (job_id, channel_id)
+VALUES (?, ?);
+
+-- name: SetDiscordReplyMessage :exec
+UPDATE discord_replies SET message_id = ? WHERE job_id = ?;
+
+-- name: GetDiscordReply :one
+SELECT * FROM discord_replies WHERE job_id = ?;
\ No newline at end of file
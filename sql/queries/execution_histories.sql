@@ -1,6 +1,10 @@
 -- name: CreateExecutionHistory :one
-INSERT INTO execution_histories (webhook_id, api_key_id, prompt, response, error, success, execution_time_ms)
-VALUES (?, ?, ?, ?, ?, ?, ?)
+INSERT INTO execution_histories (
+    webhook_id, api_key_id, prompt, response, error, success, execution_time_ms, session_id,
+    input_tokens, output_tokens, cache_read_input_tokens, cache_creation_input_tokens,
+    total_cost_usd, num_turns, duration_ms
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: ListExecutionHistoriesByWebhook :many
@@ -0,0 +1,24 @@
+-- name: GetGitHubIntegration :one
+SELECT * FROM github_integrations WHERE webhook_id = ?;
+
+-- name: UpsertGitHubIntegration :exec
+INSERT INTO github_integrations (webhook_id, secret, events, trigger_phrase, prompt_template, allowed_associations)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (webhook_id) DO UPDATE SET
+    secret = excluded.secret,
+    events = excluded.events,
+    trigger_phrase = excluded.trigger_phrase,
+    prompt_template = excluded.prompt_template,
+    allowed_associations = excluded.allowed_associations,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteGitHubIntegration :exec
+DELETE FROM github_integrations WHERE webhook_id = ?;
+
+-- name: CreateGitHubReply :exec
+-- Remembers the issue or pull request a job was triggered from so its result can be commented there
+INSERT INTO github_replies (job_id, repository, issue_number)
+VALUES (?, ?, ?);
+
+-- name: GetGitHubReply :one
+SELECT * FROM github_replies WHERE job_id = ?;
\ No newline at end of file
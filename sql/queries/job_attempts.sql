@@ -0,0 +1,8 @@
+-- name: CreateJobAttempt :exec
+INSERT INTO job_attempts (job_id, error_message, retryable)
+VALUES (?, ?, ?);
+
+-- name: ListJobAttempts :many
+SELECT * FROM job_attempts
+WHERE job_id = ?
+ORDER BY id ASC;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: CreateJobEvent :exec
+INSERT INTO job_events (job_id, event_type, payload)
+VALUES (?, ?, ?);
+
+-- name: ListJobEventsAfter :many
+SELECT * FROM job_events
+WHERE job_id = ? AND id > ?
+ORDER BY id ASC;
\ No newline at end of file
@@ -17,9 +17,17 @@ INSERT INTO job_queue (
     fallback_model,
     mcp_servers,
     enable_continue,
-    continue_minutes
+    continue_minutes,
+    callback_url,
+    callback_secret,
+    prompt_template,
+    prompt_variables,
+    resume_session_id,
+    thread,
+    trace_context,
+    request_id
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) RETURNING *;
 
 -- name: DequeueJob :one
@@ -30,35 +38,114 @@ SET
     visibility_timeout = datetime('now', '+10 minutes'),
     worker_id = ?
 WHERE id = (
-    SELECT id FROM job_queue
-    WHERE job_status = 'pending'
-       OR (job_status = 'processing' AND visibility_timeout < CURRENT_TIMESTAMP)
-    ORDER BY priority DESC, created_at ASC
+    SELECT j.id FROM job_queue j
+    JOIN webhooks w ON w.id = j.webhook_id
+    WHERE ((j.job_status = 'pending'
+            AND (j.next_attempt_at IS NULL OR j.next_attempt_at <= CURRENT_TIMESTAMP))
+       OR (j.job_status = 'processing' AND j.visibility_timeout < CURRENT_TIMESTAMP))
+      -- Respect the per-webhook concurrency limit
+      AND (
+        SELECT COUNT(*) FROM job_queue r
+        WHERE r.webhook_id = j.webhook_id
+          AND r.job_status = 'processing'
+          AND r.visibility_timeout >= CURRENT_TIMESTAMP
+      ) < w.max_concurrency
+      -- Never run two Claude sessions in the same working directory. Jobs
+      -- without one are only limited by the pool and max_concurrency.
+      AND (COALESCE(j.working_dir, '') = '' OR NOT EXISTS (
+        SELECT 1 FROM job_queue r
+        WHERE r.job_status = 'processing'
+          AND r.visibility_timeout >= CURRENT_TIMESTAMP
+          AND r.working_dir = j.working_dir
+      ))
+    ORDER BY j.priority DESC, j.created_at ASC
     LIMIT 1
 )
 RETURNING *;
 
--- name: CompleteJob :exec
+-- name: ExtendJobVisibility :execrows
+UPDATE job_queue
+SET visibility_timeout = datetime('now', '+10 minutes')
+WHERE id = ? AND worker_id = ? AND job_status = 'processing';
+
+-- name: CompleteJob :execrows
 UPDATE job_queue
 SET 
     job_status = 'completed',
     completed_at = CURRENT_TIMESTAMP,
     response = ?,
-    execution_time_ms = ?
-WHERE id = ? AND job_status = 'processing';
+    execution_time_ms = ?,
+    session_id = ?
+WHERE id = ? AND worker_id = ? AND job_status = 'processing';
 
--- name: FailJob :exec
+-- name: FailJob :execrows
+-- Retryable failures go back to pending until max_retries is reached and
+-- are not picked up again before the backoff delay has passed
 UPDATE job_queue
 SET 
     job_status = CASE 
-        WHEN retry_count >= max_retries THEN 'failed'
+        WHEN NOT sqlc.arg(retryable) OR retry_count >= max_retries THEN 'failed'
         ELSE 'pending'
     END,
+    next_attempt_at = datetime('now', printf('+%d seconds', sqlc.arg(backoff_seconds))),
     retry_count = retry_count + 1,
-    error_message = ?,
+    error_message = sqlc.arg(error_message),
     visibility_timeout = NULL,
     worker_id = NULL
-WHERE id = ? AND job_status = 'processing';
+WHERE id = sqlc.arg(id) AND worker_id = sqlc.arg(worker_id) AND job_status = 'processing';
+
+-- name: CancelJob :one
+UPDATE job_queue
+SET 
+    job_status = 'cancelled',
+    completed_at = CURRENT_TIMESTAMP,
+    visibility_timeout = NULL
+WHERE id = ? AND job_status IN ('pending', 'processing')
+RETURNING *;
+
+-- name: ListDeadLetterJobs :many
+SELECT j.*, w.name AS webhook_name
+FROM job_queue j
+JOIN webhooks w ON w.id = j.webhook_id
+WHERE j.job_status = 'failed'
+  AND (sqlc.narg(webhook_id) IS NULL OR j.webhook_id = sqlc.narg(webhook_id))
+  AND (sqlc.narg(error_contains) IS NULL OR j.error_message LIKE '%' || sqlc.narg(error_contains) || '%')
+ORDER BY j.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: RequeueJob :one
+UPDATE job_queue
+SET 
+    job_status = 'pending',
+    retry_count = 0,
+    next_attempt_at = NULL,
+    error_message = NULL,
+    worker_id = NULL,
+    started_at = NULL
+WHERE id = ? AND job_status = 'failed'
+RETURNING *;
+
+-- name: RequeueDeadLetterJobs :execrows
+UPDATE job_queue
+SET 
+    job_status = 'pending',
+    retry_count = 0,
+    next_attempt_at = NULL,
+    error_message = NULL,
+    worker_id = NULL,
+    started_at = NULL
+WHERE job_status = 'failed'
+  AND (sqlc.narg(webhook_id) IS NULL OR webhook_id = sqlc.narg(webhook_id))
+  AND (sqlc.narg(error_contains) IS NULL OR error_message LIKE '%' || sqlc.narg(error_contains) || '%');
+
+-- name: DiscardJob :execrows
+DELETE FROM job_queue WHERE id = ? AND job_status = 'failed';
+
+-- name: DiscardDeadLetterJobs :execrows
+DELETE FROM job_queue
+WHERE job_status = 'failed'
+  AND (sqlc.narg(webhook_id) IS NULL OR webhook_id = sqlc.narg(webhook_id))
+  AND (sqlc.narg(error_contains) IS NULL OR error_message LIKE '%' || sqlc.narg(error_contains) || '%');
 
 -- name: ResetStaleJobs :exec
 UPDATE job_queue
@@ -84,4 +171,34 @@ LIMIT ?;
 SELECT * FROM job_queue
 WHERE webhook_id = ?
 ORDER BY created_at DESC
-LIMIT ?;
\ No newline at end of file
+LIMIT ?;
+
+-- name: GetThreadSessionID :one
+-- Returns the session the latest completed job of a named thread ended in
+SELECT session_id FROM job_queue
+WHERE webhook_id = ? AND thread = ? AND session_id IS NOT NULL
+ORDER BY completed_at DESC, id DESC
+LIMIT 1;
+
+-- name: HasWebhookSession :one
+SELECT EXISTS (
+    SELECT 1 FROM job_queue WHERE webhook_id = ? AND session_id = ?
+);
+
+-- name: AddJobUsage :exec
+-- Adds what an attempt consumed; retried jobs accumulate the usage of every attempt
+UPDATE job_queue
+SET
+    input_tokens = input_tokens + ?,
+    output_tokens = output_tokens + ?,
+    cache_read_input_tokens = cache_read_input_tokens + ?,
+    cache_creation_input_tokens = cache_creation_input_tokens + ?,
+    total_cost_usd = total_cost_usd + ?,
+    num_turns = num_turns + ?,
+    duration_ms = duration_ms + ?
+WHERE id = ?;
+
+-- name: CountJobsByStatus :many
+SELECT job_status, COUNT(*) AS count
+FROM job_queue
+GROUP BY job_status;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: CreateJobUsage :exec
+-- Records what an attempt consumed apart from its job, which may be discarded
+INSERT INTO job_usage (
+    job_id, webhook_id, input_tokens, output_tokens,
+    cache_read_input_tokens, cache_creation_input_tokens,
+    total_cost_usd, num_turns, duration_ms, job_created_at
+)
+SELECT id, webhook_id, sqlc.arg(input_tokens), sqlc.arg(output_tokens),
+    sqlc.arg(cache_read_input_tokens), sqlc.arg(cache_creation_input_tokens),
+    sqlc.arg(total_cost_usd), sqlc.arg(num_turns), sqlc.arg(duration_ms), created_at
+FROM job_queue
+WHERE id = sqlc.arg(job_id);
+
+-- name: GetWebhookCost :one
+-- Sums the cost of the webhook's jobs created since the given time, including discarded jobs
+SELECT CAST(COALESCE(SUM(total_cost_usd), 0) AS REAL) AS total_cost_usd
+FROM job_usage
+WHERE webhook_id = ? AND job_created_at >= ?;
+
+-- name: GetWebhookUsageStats :one
+SELECT
+    CAST(COALESCE(SUM(input_tokens), 0) AS INTEGER) AS input_tokens,
+    CAST(COALESCE(SUM(output_tokens), 0) AS INTEGER) AS output_tokens,
+    CAST(COALESCE(SUM(cache_read_input_tokens), 0) AS INTEGER) AS cache_read_input_tokens,
+    CAST(COALESCE(SUM(cache_creation_input_tokens), 0) AS INTEGER) AS cache_creation_input_tokens,
+    CAST(COALESCE(SUM(total_cost_usd), 0) AS REAL) AS total_cost_usd,
+    CAST(COALESCE(SUM(num_turns), 0) AS INTEGER) AS num_turns
+FROM job_usage
+WHERE webhook_id = ? AND job_created_at >= ?;
\ No newline at end of file
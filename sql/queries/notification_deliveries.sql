@@ -0,0 +1,61 @@
+-- name: CreateNotificationDelivery :exec
+INSERT INTO notification_deliveries (job_id, notifier, config, payload, max_attempts, trace_context, request_id)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetNotificationDelivery :one
+SELECT * FROM notification_deliveries
+WHERE id = ?;
+
+-- name: ListNotificationDeliveries :many
+SELECT * FROM notification_deliveries
+WHERE job_id = ?
+ORDER BY id ASC;
+
+-- name: ClaimNotificationDelivery :one
+-- Marks the oldest due delivery as sending and counts the attempt
+UPDATE notification_deliveries
+SET status = 'sending',
+    attempts = attempts + 1
+WHERE id = (
+    SELECT id FROM notification_deliveries
+    WHERE status = 'pending'
+      AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY next_attempt_at ASC, id ASC
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: CompleteNotificationDelivery :exec
+UPDATE notification_deliveries
+SET status = 'delivered',
+    last_error = NULL,
+    delivered_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: FailNotificationDelivery :exec
+-- Records a failed attempt; status is 'pending' to retry after delay_seconds or 'failed' to give up.
+-- sent_messages counts the messages of the notification that have been posted, so a retry skips them.
+UPDATE notification_deliveries
+SET status = sqlc.arg(status),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = datetime('now', printf('+%d seconds', sqlc.arg(delay_seconds))),
+    sent_messages = sqlc.arg(sent_messages)
+WHERE id = sqlc.arg(id);
+
+-- name: ResendNotificationDelivery :one
+-- Queues a delivered or failed notification again with a fresh attempt budget
+UPDATE notification_deliveries
+SET status = 'pending',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = CURRENT_TIMESTAMP,
+    delivered_at = NULL,
+    sent_messages = 0
+WHERE id = ? AND status IN ('delivered', 'failed')
+RETURNING *;
+
+-- name: ResetSendingNotificationDeliveries :exec
+-- Returns deliveries interrupted by a shutdown to the queue
+UPDATE notification_deliveries
+SET status = 'pending'
+WHERE status = 'sending';
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: CreateSlackReply :exec
+-- Remembers where the result of a job submitted from Slack is posted
+INSERT INTO slack_replies (job_id, response_url, channel_id, thread_ts)
+VALUES (?, ?, ?, ?);
+
+-- name: GetSlackReply :one
+SELECT * FROM slack_replies WHERE job_id = ?;
\ No newline at end of file
@@ -12,9 +12,14 @@ INSERT INTO webhooks (
     allowed_tools, disallowed_tools,
     permission_mode, permission_prompt_tool_name,
     model, fallback_model, mcp_servers,
-    enable_continue, continue_minutes
+    enable_continue, continue_minutes,
+    max_concurrency,
+    retry_backoff_base_seconds, retry_backoff_factor,
+    retry_backoff_jitter, retry_backoff_max_seconds,
+    prompt_template, override_policy,
+    daily_budget_usd, monthly_budget_usd
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: UpdateWebhook :exec
@@ -36,6 +41,15 @@ SET name = ?,
     mcp_servers = ?,
     enable_continue = ?,
     continue_minutes = ?,
+    max_concurrency = ?,
+    retry_backoff_base_seconds = ?,
+    retry_backoff_factor = ?,
+    retry_backoff_jitter = ?,
+    retry_backoff_max_seconds = ?,
+    prompt_template = ?,
+    override_policy = ?,
+    daily_budget_usd = ?,
+    monthly_budget_usd = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
@@ -23,7 +23,16 @@ CREATE TABLE IF NOT EXISTS webhooks (
     mcp_servers TEXT,
     notification_config JSON,
     enable_continue BOOLEAN NOT NULL DEFAULT 1,
-    continue_minutes INTEGER NOT NULL DEFAULT 10
+    continue_minutes INTEGER NOT NULL DEFAULT 10,
+    max_concurrency INTEGER NOT NULL DEFAULT 1,
+    retry_backoff_base_seconds INTEGER NOT NULL DEFAULT 30,
+    retry_backoff_factor REAL NOT NULL DEFAULT 2.0,
+    retry_backoff_jitter REAL NOT NULL DEFAULT 0.2,
+    retry_backoff_max_seconds INTEGER NOT NULL DEFAULT 3600,
+    prompt_template TEXT,
+    override_policy TEXT,
+    daily_budget_usd REAL,
+    monthly_budget_usd REAL
 );
 
 -- Create api_keys table  
@@ -51,6 +60,14 @@ CREATE TABLE IF NOT EXISTS execution_histories (
     success BOOLEAN NOT NULL,
     execution_time_ms INTEGER,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    session_id TEXT,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
+    total_cost_usd REAL NOT NULL DEFAULT 0,
+    num_turns INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
     FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE SET NULL
 );
@@ -87,10 +104,147 @@ CREATE TABLE IF NOT EXISTS job_queue (
     mcp_servers TEXT,
     enable_continue BOOLEAN NOT NULL DEFAULT 0,
     continue_minutes INTEGER NOT NULL DEFAULT 10,
+    next_attempt_at DATETIME,
+    callback_url TEXT,
+    callback_secret TEXT,
+    prompt_template TEXT,
+    prompt_variables TEXT,
+    resume_session_id TEXT,
+    thread TEXT,
+    session_id TEXT,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
+    total_cost_usd REAL NOT NULL DEFAULT 0,
+    num_turns INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    trace_context TEXT,
+    request_id TEXT,
     FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
     FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE SET NULL
 );
 
+-- Create job_events table
+CREATE TABLE IF NOT EXISTS job_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
+-- Create job_attempts table
+CREATE TABLE IF NOT EXISTS job_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    error_message TEXT NOT NULL,
+    retryable BOOLEAN NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
+-- Create job_usage table
+-- Usage of each attempt, kept when its job is discarded so budgets still count it
+CREATE TABLE IF NOT EXISTS job_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    webhook_id TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_input_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_creation_input_tokens INTEGER NOT NULL DEFAULT 0,
+    total_cost_usd REAL NOT NULL DEFAULT 0,
+    num_turns INTEGER NOT NULL DEFAULT 0,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    job_created_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+-- Create callback_deliveries table
+CREATE TABLE IF NOT EXISTS callback_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error_message TEXT,
+    success BOOLEAN NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
+-- Create notification_deliveries table
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    notifier TEXT NOT NULL,
+    config TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME,
+    trace_context TEXT,
+    request_id TEXT,
+    sent_messages INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
+-- Create discord_channels table
+CREATE TABLE IF NOT EXISTS discord_channels (
+    channel_id TEXT PRIMARY KEY,
+    webhook_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+-- Create discord_replies table
+CREATE TABLE IF NOT EXISTS discord_replies (
+    job_id INTEGER PRIMARY KEY,
+    channel_id TEXT NOT NULL,
+    message_id TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
+-- Create slack_replies table
+CREATE TABLE IF NOT EXISTS slack_replies (
+    job_id INTEGER PRIMARY KEY,
+    response_url TEXT,
+    channel_id TEXT,
+    thread_ts TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
+-- Create github_integrations table
+CREATE TABLE IF NOT EXISTS github_integrations (
+    webhook_id TEXT PRIMARY KEY,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT 'issue_comment',
+    trigger_phrase TEXT NOT NULL DEFAULT '@claude',
+    prompt_template TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    allowed_associations TEXT NOT NULL DEFAULT 'OWNER,MEMBER,COLLABORATOR',
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+-- Create github_replies table
+CREATE TABLE IF NOT EXISTS github_replies (
+    job_id INTEGER PRIMARY KEY,
+    repository TEXT NOT NULL,
+    issue_number INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES job_queue(id) ON DELETE CASCADE
+);
+
 -- Create security_audit_logs table
 CREATE TABLE IF NOT EXISTS security_audit_logs (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -121,6 +275,14 @@ CREATE INDEX idx_job_queue_status ON job_queue(job_status);
 CREATE INDEX idx_job_queue_webhook_id ON job_queue(webhook_id);
 CREATE INDEX idx_job_queue_created_at ON job_queue(created_at);
 CREATE INDEX idx_job_queue_visibility_timeout ON job_queue(visibility_timeout);
+CREATE INDEX idx_job_queue_webhook_thread ON job_queue(webhook_id, thread);
+CREATE INDEX idx_job_events_job_id ON job_events(job_id);
+CREATE INDEX idx_job_attempts_job_id ON job_attempts(job_id);
+CREATE INDEX idx_job_usage_webhook_job_created_at ON job_usage(webhook_id, job_created_at);
+CREATE INDEX idx_callback_deliveries_job_id ON callback_deliveries(job_id);
+CREATE INDEX idx_notification_deliveries_job_id ON notification_deliveries(job_id);
+CREATE INDEX idx_notification_deliveries_status_next_attempt ON notification_deliveries(status, next_attempt_at);
+CREATE INDEX idx_discord_channels_webhook_id ON discord_channels(webhook_id);
 CREATE INDEX idx_security_audit_logs_webhook_id ON security_audit_logs(webhook_id);
 CREATE INDEX idx_security_audit_logs_created_at ON security_audit_logs(created_at);
 CREATE INDEX idx_security_audit_logs_event_type ON security_audit_logs(event_type);